@@ -0,0 +1,422 @@
+package sabre
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	// Go implements (go expr*) form. The forms are evaluated in a new
+	// goroutine with a child scope. Returns a channel that receives the
+	// result and is closed after. If the evaluation fails, receiving from
+	// the channel returns the error.
+	Go = SpecialForm{
+		Name:  "go",
+		Parse: parseGo,
+	}
+
+	// Future implements (future expr*) form. The forms are evaluated in a
+	// new goroutine with a child scope. Returns a Promise that is delivered
+	// the result of the evaluation. See deref.
+	Future = SpecialForm{
+		Name:  "future",
+		Parse: parseFuture,
+	}
+)
+
+// NewChan returns a channel of values with given buffer size.
+func NewChan(size int) *Chan {
+	return &Chan{ch: reflect.ValueOf(make(chan Value, size))}
+}
+
+// Chan represents a Go channel. Channels created using NewChan() (or 'chan')
+// carry any Value. Go channels of other types are wrapped by ValueOf() and
+// values are converted to the element type when sending.
+type Chan struct {
+	ch reflect.Value
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// Eval returns the channel itself.
+func (c *Chan) Eval(_ Scope) (Value, error) { return c, nil }
+
+func (c *Chan) String() string { return fmt.Sprintf("Chan{%s}", c.ch.Type()) }
+
+func (c *Chan) goValue() reflect.Value { return c.ch }
+
+// Send puts the value into the channel. Blocks until the value is received
+// or buffered or the context is done.
+func (c *Chan) Send(ctx context.Context, v Value) error {
+	_, _, err := Select(ctx, SelectCase{Chan: c, Send: v})
+	return err
+}
+
+// Receive takes a value from the channel. Blocks until a value is available,
+// the channel is closed or the context is done. Returns nil if the channel
+// is closed.
+func (c *Chan) Receive(ctx context.Context) (Value, error) {
+	_, v, err := Select(ctx, SelectCase{Chan: c})
+	return v, err
+}
+
+// Close closes the channel. Closing a closed channel has no effect.
+func (c *Chan) Close() error {
+	return c.closeWithErr(nil)
+}
+
+// deliver sends the result of a 'go' evaluation (unless it failed) and
+// closes the channel. Since the channel may be closed already (e.g., using
+// close!), sending and closing hold the lock used by closeWithErr(). The
+// send does not block so that the lock is never held indefinitely: the
+// result is dropped if the channel is closed or its buffer is full (e.g.,
+// after a '>!' to the channel).
+func (c *Chan) deliver(v Value, cause error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	if cause == nil {
+		c.ch.TrySend(reflect.ValueOf(&v).Elem())
+	}
+
+	c.ch.Close()
+	c.closed, c.err = true, cause
+}
+
+func (c *Chan) closeWithErr(cause error) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	c.ch.Close()
+	c.closed, c.err = true, cause
+	return nil
+}
+
+// SelectCase represents a send (if Send is not nil) or receive operation
+// on a channel for Select().
+type SelectCase struct {
+	Chan *Chan
+	Send Value
+}
+
+// Select blocks until one of the operations can proceed or the context is
+// done. Returns the index of the case that proceeded and the value received
+// (nil for send operations and closed channels). If a value was received
+// from a channel that was closed due to failure of a 'go' evaluation, the
+// failure is returned as error.
+func Select(ctx context.Context, cases ...SelectCase) (int, Value, error) {
+	selectCases := make([]reflect.SelectCase, 0, len(cases)+1)
+	for _, sc := range cases {
+		if sc.Send == nil {
+			selectCases = append(selectCases, reflect.SelectCase{
+				Dir:  reflect.SelectRecv,
+				Chan: sc.Chan.ch,
+			})
+			continue
+		}
+
+		converted, err := convertArgsTo(sc.Chan.ch.Type().Elem(), reflectValues([]Value{sc.Send})...)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		selectCases = append(selectCases, reflect.SelectCase{
+			Dir:  reflect.SelectSend,
+			Chan: sc.Chan.ch,
+			Send: converted[0],
+		})
+	}
+
+	selectCases = append(selectCases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	})
+
+	chosen, rv, ok, err := trySelect(selectCases)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	if chosen == len(cases) {
+		return -1, nil, fmt.Errorf("evaluation cancelled: %w", ctx.Err())
+	}
+
+	if cases[chosen].Send != nil {
+		// orders the send before a later close of the channel by another
+		// goroutine (which holds the lock) for the race detector.
+		c := cases[chosen].Chan
+		c.mu.Lock()
+		c.mu.Unlock()
+		return chosen, nil, nil
+	}
+
+	if !ok {
+		c := cases[chosen].Chan
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return chosen, Nil{}, c.err
+	}
+
+	return chosen, ValueOf(rv.Interface()), nil
+}
+
+func trySelect(cases []reflect.SelectCase) (chosen int, rv reflect.Value, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	chosen, rv, ok = reflect.Select(cases)
+	return chosen, rv, ok, nil
+}
+
+// NewPromise returns a promise that can be delivered a value once.
+func NewPromise() *Promise {
+	return &Promise{done: make(chan struct{})}
+}
+
+// Promise represents a value that becomes available later. Promises are
+// created using 'promise' and delivered using 'deliver', or created using
+// 'future' and delivered the result of the evaluation.
+type Promise struct {
+	once sync.Once
+	done chan struct{}
+	val  Value
+	err  error
+}
+
+// Eval returns the promise itself.
+func (p *Promise) Eval(_ Scope) (Value, error) { return p, nil }
+
+func (p *Promise) String() string {
+	select {
+	case <-p.done:
+		if p.err != nil {
+			return "Promise{error}"
+		}
+		return "Promise{" + PrStr(p.val) + "}"
+
+	default:
+		return "Promise{pending}"
+	}
+}
+
+// Deliver sets the value of the promise and unblocks Deref(). Returns false
+// if the promise was already delivered.
+func (p *Promise) Deliver(v Value) bool {
+	return p.resolve(v, nil)
+}
+
+// Deref returns the value of the promise. Blocks until the promise is
+// delivered, the timeout elapses (if timeout > 0) or the context is done.
+// Returns false if the timeout elapsed.
+func (p *Promise) Deref(ctx context.Context, timeout time.Duration) (Value, bool, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-p.done:
+		return p.val, true, p.err
+
+	case <-expired:
+		return nil, false, nil
+
+	case <-ctx.Done():
+		return nil, false, fmt.Errorf("evaluation cancelled: %w", ctx.Err())
+	}
+}
+
+func (p *Promise) resolve(v Value, err error) bool {
+	delivered := false
+	p.once.Do(func() {
+		p.val, p.err = v, err
+		close(p.done)
+		delivered = true
+	})
+	return delivered
+}
+
+func parseGo(scope Scope, args []Value) (*Fn, error) {
+	if err := analyzeSeq(scope, Values(args)); err != nil {
+		return nil, err
+	}
+
+	return &Fn{
+		Func: func(scope Scope, _ []Value) (Value, error) {
+			ch := NewChan(1)
+			spawn(scope, args, ch.deliver)
+			return ch, nil
+		},
+	}, nil
+}
+
+func parseFuture(scope Scope, args []Value) (*Fn, error) {
+	if err := analyzeSeq(scope, Values(args)); err != nil {
+		return nil, err
+	}
+
+	return &Fn{
+		Func: func(scope Scope, _ []Value) (Value, error) {
+			p := NewPromise()
+			spawn(scope, args, func(v Value, err error) {
+				p.resolve(v, err)
+			})
+			return p, nil
+		},
+	}, nil
+}
+
+// spawn evaluates the forms in a new goroutine with a child scope. Since the
+// child scope inherits the context of the scope (See ContextOf()), the
+// evaluation stops when the context of the parent evaluation is cancelled.
+func spawn(scope Scope, forms []Value, done func(v Value, err error)) {
+	child := NewScope(scope)
+	go func() {
+		done(Module(forms).Eval(child))
+	}()
+}
+
+func bindAsync(scope Scope) {
+	_ = scope.Bind("go", Go)
+	_ = scope.Bind("future", Future)
+
+	_ = scope.Bind("chan", ValueOf(func(size ...int) (*Chan, error) {
+		if len(size) > 1 {
+			return nil, fmt.Errorf("call requires at-most 1 argument, got %d", len(size))
+		}
+
+		if len(size) == 0 {
+			return NewChan(0), nil
+		}
+		return NewChan(size[0]), nil
+	}))
+
+	_ = scope.Bind(">!", ValueOf(func(scope Scope, c *Chan, v Value) (Bool, error) {
+		if err := c.Send(ContextOf(scope), v); err != nil {
+			return false, err
+		}
+		return true, nil
+	}))
+
+	_ = scope.Bind("<!", ValueOf(func(scope Scope, c *Chan) (Value, error) {
+		return c.Receive(ContextOf(scope))
+	}))
+
+	_ = scope.Bind("close!", ValueOf(func(c *Chan) error {
+		return c.Close()
+	}))
+
+	_ = scope.Bind("timeout", ValueOf(func(ms int) *Chan {
+		c := NewChan(0)
+		time.AfterFunc(time.Duration(ms)*time.Millisecond, func() {
+			_ = c.Close()
+		})
+		return c
+	}))
+
+	alts := ValueOf(alts)
+	_ = scope.Bind("alts!", alts)
+	_ = scope.Bind("select", alts)
+
+	_ = scope.Bind("promise", ValueOf(NewPromise))
+
+	_ = scope.Bind("deliver", ValueOf(func(p *Promise, v Value) Value {
+		if p.Deliver(v) {
+			return p
+		}
+		return Nil{}
+	}))
+
+	_ = scope.Bind("deref", ValueOf(deref))
+}
+
+// alts implements (alts! [port*]) where each port is either a channel to
+// receive from or a [channel value] vector to send to. Blocks until one of
+// the operations proceeds and returns [value port]. Use (timeout ms) as a
+// port to limit the wait.
+func alts(scope Scope, ports Vector) (Value, error) {
+	cases := make([]SelectCase, len(ports.Values))
+	for i, port := range ports.Values {
+		switch p := port.(type) {
+		case *Chan:
+			cases[i] = SelectCase{Chan: p}
+
+		case Vector:
+			c, isChan := p.Values.First().(*Chan)
+			if len(p.Values) != 2 || !isChan {
+				return nil, fmt.Errorf("put port must be [channel value], not %s", p)
+			}
+			cases[i] = SelectCase{Chan: c, Send: p.Values[1]}
+
+		default:
+			return nil, fmt.Errorf("port must be a channel or [channel value], not %s",
+				reflect.TypeOf(port))
+		}
+	}
+
+	chosen, v, err := Select(ContextOf(scope), cases...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cases[chosen].Send != nil {
+		v = Bool(true)
+	}
+	return Vector{Values: Values{v, cases[chosen].Chan}}, nil
+}
+
+// deref implements (deref promise) and (deref promise timeout-ms timeout-val)
+// forms.
+func deref(scope Scope, p *Promise, opts ...Value) (Value, error) {
+	var timeout time.Duration
+	var timeoutVal Value = Nil{}
+
+	switch len(opts) {
+	case 0:
+
+	case 2:
+		ms, isInt := opts[0].(Int64)
+		if !isInt {
+			return nil, fmt.Errorf("timeout must be integer milliseconds, not %s",
+				reflect.TypeOf(opts[0]))
+		}
+		timeout, timeoutVal = time.Duration(ms)*time.Millisecond, opts[1]
+
+	default:
+		return nil, fmt.Errorf("call requires 1 or 3 arguments, got %d", len(opts)+1)
+	}
+
+	v, delivered, err := p.Deref(ContextOf(scope), timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if !delivered {
+		return timeoutVal, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,203 @@
+package sabre_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+func TestAsync(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr bool
+	}{
+		{
+			name: "Go",
+			src:  `(<! (go (def a 1) (+ a 1)))`,
+			want: sabre.Int64(2),
+		},
+		{
+			name:    "GoError",
+			src:     `(<! (go (undefined-fn)))`,
+			wantErr: true,
+		},
+		{
+			name: "GoClosedAfterResult",
+			src:  `(let* [c (go 1)] (<! c) (<! c))`,
+			want: sabre.Nil{},
+		},
+		{
+			name: "GoClosedBeforeResult",
+			src:  `(let* [c (go (sleep) 1)] (close! c) (<! (timeout 50)) (<! c))`,
+			want: sabre.Nil{},
+		},
+		{
+			name: "GoBufferFilledThenClosed",
+			src:  `(let* [c (go (<! (timeout 50)) 1)] (>! c 5) (<! (timeout 100)) (close! c) (<! c))`,
+			want: sabre.Int64(5),
+		},
+		{
+			name: "GoBufferFilledDropsResult",
+			src:  `(let* [c (go (<! (timeout 20)) 1)] (>! c 5) (<! (timeout 50)) [(<! c) (<! c)])`,
+			want: sabre.Vector{Values: []sabre.Value{sabre.Int64(5), sabre.Nil{}}},
+		},
+		{
+			name: "BufferedChan",
+			src:  `(let* [c (chan 2)] (>! c 1) (>! c 2) (close! c) [(<! c) (<! c) (<! c)])`,
+			want: sabre.Vector{Values: []sabre.Value{
+				sabre.Int64(1), sabre.Int64(2), sabre.Nil{},
+			}},
+		},
+		{
+			name: "UnbufferedChan",
+			src:  `(let* [c (chan)] (go (>! c :hello)) (<! c))`,
+			want: sabre.Keyword("hello"),
+		},
+		{
+			name:    "SendOnClosed",
+			src:     `(let* [c (chan 1)] (close! c) (>! c 1))`,
+			wantErr: true,
+		},
+		{
+			name: "AltsReceive",
+			src:  `(let* [c (chan 1)] (>! c 10) (first (alts! [(chan) c])))`,
+			want: sabre.Int64(10),
+		},
+		{
+			name: "AltsSend",
+			src:  `(let* [c (chan 1)] (select [[c 1]]) (<! c))`,
+			want: sabre.Int64(1),
+		},
+		{
+			name: "AltsTimeout",
+			src:  `(let* [t (timeout 10) r (alts! [(chan) t])] (= t (second r)))`,
+			want: sabre.Bool(true),
+		},
+		{
+			name:    "AltsInvalidPort",
+			src:     `(alts! [1])`,
+			wantErr: true,
+		},
+		{
+			name: "Future",
+			src:  `(deref (future (def b 10) b))`,
+			want: sabre.Int64(10),
+		},
+		{
+			name:    "FutureError",
+			src:     `(deref (future (undefined-fn)))`,
+			wantErr: true,
+		},
+		{
+			name: "Promise",
+			src:  `(let* [p (promise)] (go (deliver p 5)) (deref p))`,
+			want: sabre.Int64(5),
+		},
+		{
+			name: "DeliverTwice",
+			src:  `(let* [p (promise)] (deliver p 1) [(deliver p 2) (deref p)])`,
+			want: sabre.Vector{Values: []sabre.Value{sabre.Nil{}, sabre.Int64(1)}},
+		},
+		{
+			name: "DerefTimeout",
+			src:  `(deref (promise) 10 :timed-out)`,
+			want: sabre.Keyword("timed-out"),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("+", func(a, b int) int { return a + b })
+			scope.BindGo("=", sabre.Compare)
+			scope.BindGo("first", func(v sabre.Vector) sabre.Value { return v.First() })
+			scope.BindGo("second", func(v sabre.Vector) sabre.Value { return v.Values[1] })
+			scope.BindGo("sleep", func() { time.Sleep(10 * time.Millisecond) })
+
+			got, err := sabre.ReadEvalStr(scope, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+
+			if !tt.wantErr && !sabre.Compare(got, tt.want) {
+				t.Errorf("Eval() want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAsync_GoChannels(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan int, 1)
+	scope := sabre.New()
+	scope.BindGo("ch", ch)
+	scope.BindGo("drain", func(c <-chan int) int { return <-c })
+
+	if _, err := sabre.ReadEvalStr(scope, "(>! ch 10)"); err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	if got := <-ch; got != 10 {
+		t.Errorf("expected 10 to be sent, got %d", got)
+	}
+
+	ch <- 20
+	got, err := sabre.ReadEvalStr(scope, "(<! ch)")
+	if err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	if got != sabre.Int64(20) {
+		t.Errorf("expected 20 to be received, got %s", got)
+	}
+
+	ch <- 30
+	got, err = sabre.ReadEvalStr(scope, "(drain ch)")
+	if err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	if got != sabre.Int64(30) {
+		t.Errorf("expected 30 to be received, got %s", got)
+	}
+
+	if _, err := sabre.ReadEvalStr(scope, `(>! ch "hello")`); err == nil {
+		t.Errorf("expected error sending string to chan int")
+	}
+}
+
+func TestAsync_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scope := sabre.WithContext(ctx, sabre.New())
+
+	v, err := sabre.ReadEvalStr(scope, "[(go (<! (chan))) (future (<! (chan)))]")
+	if err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+	children := v.(sabre.Vector).Values
+
+	cancel()
+
+	timeout, stop := context.WithTimeout(context.Background(), time.Second)
+	defer stop()
+
+	_, err = children[0].(*sabre.Chan).Receive(timeout)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected go block to be cancelled, got %v", err)
+	}
+
+	_, _, err = children[1].(*sabre.Promise).Deref(timeout, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected future to be cancelled, got %v", err)
+	}
+}
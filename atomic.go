@@ -0,0 +1,142 @@
+package sabre
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// NewAtomic initializes a new AtomicScope with all the core bindings.
+func NewAtomic() *AtomicScope {
+	scope := NewAtomicScope(nil)
+	bindCore(scope)
+	return scope
+}
+
+// NewAtomicScope returns an instance of AtomicScope with no bindings. If you
+// need builtin special forms, pass result of NewAtomic() as argument.
+func NewAtomicScope(parent Scope) *AtomicScope {
+	scope := &AtomicScope{parent: parent}
+	scope.bindings.Store(&persistentMap{})
+	return scope
+}
+
+// AtomicScope implements Scope using an immutable persistent map that is
+// swapped atomically on every Bind. Unlike MapScope, Resolve never takes a
+// lock which makes it suitable as a root scope shared by many goroutines
+// evaluating concurrently. Bind is more expensive than MapScope and calls
+// to it are serialized. Snapshot() is O(1).
+type AtomicScope struct {
+	parent   Scope
+	mu       sync.Mutex // serializes updates.
+	bindings atomic.Value
+	sealed   bool
+}
+
+// Parent returns the parent scope of this scope.
+func (scope *AtomicScope) Parent() Scope { return scope.parent }
+
+// Bind adds the given value to the scope and binds the symbol to it.
+func (scope *AtomicScope) Bind(symbol string, v Value) error {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot bind %v", ErrSealed, symbol)
+	}
+
+	scope.bindings.Store(scope.load().set(symbol, v))
+	return nil
+}
+
+// BindGo is similar to Bind but handles conversion of Go value 'v' to
+// sabre Value type. See `ValueOf()`
+func (scope *AtomicScope) BindGo(symbol string, v interface{}) error {
+	return scope.Bind(symbol, ValueOf(v))
+}
+
+// Resolve finds the value bound to the given symbol and returns it if
+// found in this scope or parent scope if any. Returns error otherwise.
+func (scope *AtomicScope) Resolve(symbol string) (Value, error) {
+	if v, found := scope.load().get(symbol); found {
+		return v, nil
+	}
+
+	if scope.parent != nil {
+		return scope.parent.Resolve(symbol)
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrResolving, symbol)
+}
+
+// Unbind removes the binding of the symbol from this scope. Returns error if
+// the symbol is not bound in this scope.
+func (scope *AtomicScope) Unbind(symbol string) error {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot unbind %v", ErrSealed, symbol)
+	}
+
+	bindings, removed := scope.load().delete(symbol)
+	if !removed {
+		return fmt.Errorf("%w: %v", ErrResolving, symbol)
+	}
+
+	scope.bindings.Store(bindings)
+	return nil
+}
+
+// Bindings returns a copy of the bindings in this scope. Bindings of the
+// parent scope are not included.
+func (scope *AtomicScope) Bindings() map[string]Value {
+	m := scope.load()
+
+	bindings := make(map[string]Value, m.size)
+	m.each(func(symbol string, v Value) {
+		bindings[symbol] = v
+	})
+	return bindings
+}
+
+// Snapshot captures the bindings in this scope without copying them.
+func (scope *AtomicScope) Snapshot() Snapshot {
+	return atomicSnapshot{bindings: scope.load()}
+}
+
+// Restore replaces the bindings in this scope with the ones captured using
+// Snapshot() of an AtomicScope.
+func (scope *AtomicScope) Restore(snapshot Snapshot) error {
+	snap, ok := snapshot.(atomicSnapshot)
+	if !ok {
+		return fmt.Errorf("cannot restore snapshot of type %T", snapshot)
+	}
+
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot restore", ErrSealed)
+	}
+
+	scope.bindings.Store(snap.bindings)
+	return nil
+}
+
+// Seal makes the bindings of this scope immutable. Bind, Unbind and Restore
+// return ErrSealed after sealing. Sealing is irreversible.
+func (scope *AtomicScope) Seal() {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	scope.sealed = true
+}
+
+func (scope *AtomicScope) load() *persistentMap {
+	return scope.bindings.Load().(*persistentMap)
+}
+
+type atomicSnapshot struct {
+	bindings *persistentMap
+}
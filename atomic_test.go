@@ -0,0 +1,149 @@
+package sabre_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+var (
+	_ sabre.Scope         = (*sabre.AtomicScope)(nil)
+	_ sabre.BindingLister = (*sabre.AtomicScope)(nil)
+	_ sabre.Unbinder      = (*sabre.AtomicScope)(nil)
+	_ sabre.Snapshotter   = (*sabre.AtomicScope)(nil)
+	_ sabre.Sealer        = (*sabre.AtomicScope)(nil)
+)
+
+func TestAtomicScope(t *testing.T) {
+	t.Parallel()
+
+	const count = 5000
+
+	scope := sabre.NewAtomicScope(nil)
+	want := map[string]sabre.Value{}
+	for i := 0; i < count; i++ {
+		sym := fmt.Sprintf("sym-%d", i)
+		_ = scope.Bind(sym, sabre.Int64(i))
+		want[sym] = sabre.Int64(i)
+	}
+	snapshot := scope.Snapshot()
+
+	for i := 0; i < count; i += 2 {
+		sym := fmt.Sprintf("sym-%d", i)
+		if err := scope.Unbind(sym); err != nil {
+			t.Fatalf("Unbind() unexpected error: %v", err)
+		}
+		delete(want, sym)
+	}
+	_ = scope.Bind("sym-1", sabre.String("updated"))
+	want["sym-1"] = sabre.String("updated")
+
+	if got := scope.Bindings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings() expected %d bindings, got %d", len(want), len(got))
+	}
+
+	if err := scope.Unbind("sym-0"); !errors.Is(err, sabre.ErrResolving) {
+		t.Errorf("Unbind() expected ErrResolving, got %v", err)
+	}
+
+	if err := scope.Restore(snapshot); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+
+	for i := 0; i < count; i++ {
+		got, err := scope.Resolve(fmt.Sprintf("sym-%d", i))
+		if err != nil || got != sabre.Int64(i) {
+			t.Fatalf("Resolve() after Restore() got = %v, err = %v", got, err)
+		}
+	}
+
+	scope.Seal()
+	if err := scope.Bind("x", sabre.Nil{}); !errors.Is(err, sabre.ErrSealed) {
+		t.Errorf("Bind() expected ErrSealed, got %v", err)
+	}
+}
+
+func TestAtomicScope_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	root := sabre.NewAtomic()
+	_ = root.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+	_ = root.BindGo("dec", func(i sabre.Int64) sabre.Int64 { return i - 1 })
+	_ = root.BindGo("zero?", func(i sabre.Int64) bool { return i == 0 })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			src := fmt.Sprintf(`(def f%d (fn* [n acc] (if (zero? n) acc (recur (dec n) (inc acc)))))
+				(let* [x %d y (inc x)] (f%d 3 y))`, i, i, i)
+			for j := 0; j < 50; j++ {
+				got, err := sabre.ReadEvalStr(sabre.NewScope(root), src)
+				if err != nil {
+					t.Errorf("ReadEvalStr() unexpected error: %v", err)
+					return
+				}
+
+				if got != sabre.Int64(i+4) {
+					t.Errorf("ReadEvalStr() want=%d, got=%v", i+4, got)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkScope_Resolve(b *testing.B) {
+	scopes := map[string]func(parent sabre.Scope) sabre.Scope{
+		"MapScope":    func(parent sabre.Scope) sabre.Scope { return sabre.NewScope(parent) },
+		"AtomicScope": func(parent sabre.Scope) sabre.Scope { return sabre.NewAtomicScope(parent) },
+	}
+
+	for name, newScope := range scopes {
+		root := newScope(nil)
+		for i := 0; i < 500; i++ {
+			_ = root.Bind(fmt.Sprintf("fn-%d", i), sabre.Int64(i))
+		}
+		scope := newScope(newScope(root))
+
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, _ = scope.Resolve("fn-250")
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkFn_Invoke(b *testing.B) {
+	scopes := map[string]sabre.Scope{
+		"MapScope":    sabre.New(),
+		"AtomicScope": sabre.NewAtomic(),
+	}
+
+	for name, scope := range scopes {
+		_ = scope.Bind("add", sabre.ValueOf(func(a, b sabre.Int64) sabre.Int64 { return a + b }))
+		form, err := sabre.NewReader(strings.NewReader(
+			"((fn* [a b] (let* [c (add a b) d (add c a)] (add c d))) 1 2)")).All()
+		if err != nil {
+			b.Fatalf("failed to read: %v", err)
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, _ = sabre.Eval(scope, form)
+				}
+			})
+		})
+	}
+}
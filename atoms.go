@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 )
 
 // Nil represents a nil value.
@@ -29,7 +30,7 @@ type Float64 float64
 // Eval simply returns itself since Floats evaluate to themselves.
 func (f64 Float64) Eval(_ Scope) (Value, error) { return f64, nil }
 
-func (f64 Float64) String() string { return fmt.Sprintf("%f", f64) }
+func (f64 Float64) String() string { return formatFloat(float64(f64)) }
 
 // Int64 represents integer values represented using decimal, octal, radix
 // and hexadecimal formats.
@@ -42,21 +43,22 @@ func (i64 Int64) String() string { return fmt.Sprintf("%d", i64) }
 
 // String represents double-quoted string literals. String Form represents
 // the true string value obtained from the reader. Escape sequences are not
-// applicable at this level.
+// applicable at this level but are re-applied by String().
 type String string
 
 // Eval simply returns itself since Strings evaluate to themselves.
 func (se String) Eval(_ Scope) (Value, error) { return se, nil }
 
-func (se String) String() string { return fmt.Sprintf("\"%s\"", string(se)) }
+func (se String) String() string { return quoteString(string(se)) }
 
-// First returns the first character if string is not empty, nil otherwise.
+// First returns the first character (rune) if string is not empty, nil
+// otherwise.
 func (se String) First() Value {
-	if len(se) == 0 {
-		return Nil{}
+	for _, r := range se {
+		return Character(r)
 	}
 
-	return Character(se[0])
+	return Nil{}
 }
 
 // Next slices the string by excluding first character and returns the
@@ -71,6 +73,9 @@ func (se String) Cons(v Value) Seq { return se.chars().Cons(v) }
 // the new sequence.
 func (se String) Conj(vals ...Value) Seq { return se.chars().Conj(vals...) }
 
+// Size returns the number of characters (runes) in the string.
+func (se String) Size() int { return utf8.RuneCountInString(string(se)) }
+
 func (se String) chars() Values {
 	var vals Values
 	for _, r := range se {
@@ -87,7 +92,7 @@ type Character rune
 // Eval simply returns itself since Chracters evaluate to themselves.
 func (char Character) Eval(_ Scope) (Value, error) { return char, nil }
 
-func (char Character) String() string { return fmt.Sprintf("\\%c", rune(char)) }
+func (char Character) String() string { return quoteChar(rune(char)) }
 
 // Keyword represents a keyword literal.
 type Keyword string
@@ -108,7 +113,7 @@ func (kw Keyword) Invoke(scope Scope, args ...Value) (Value, error) {
 		return nil, err
 	}
 
-	hm, ok := argVals[0].(*HashMap)
+	hm, ok := argVals[0].(getter)
 	if !ok {
 		return Nil{}, nil
 	}
@@ -107,11 +107,19 @@ func TestFloat64_String(t *testing.T) {
 	executeStringTestCase(t, []stringTestCase{
 		{
 			value: sabre.Float64(10.3),
-			want:  "10.300000",
+			want:  "10.3",
 		},
 		{
 			value: sabre.Float64(-10.3),
-			want:  "-10.300000",
+			want:  "-10.3",
+		},
+		{
+			value: sabre.Float64(10),
+			want:  "10.0",
+		},
+		{
+			value: sabre.Float64(1e-10),
+			want:  "1e-10",
 		},
 	})
 }
@@ -153,6 +161,10 @@ func TestCharacter_String(t *testing.T) {
 			value: sabre.Character('a'),
 			want:  "\\a",
 		},
+		{
+			value: sabre.Character('\n'),
+			want:  "\\newline",
+		},
 	})
 }
 
@@ -164,7 +176,11 @@ func TestString_String(t *testing.T) {
 		},
 		{
 			value: sabre.String("hello\tworld"),
-			want: `"hello	world"`,
+			want:  `"hello\tworld"`,
+		},
+		{
+			value: sabre.String(`say "hi"`),
+			want:  `"say \"hi\""`,
 		},
 	})
 }
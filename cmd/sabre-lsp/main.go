@@ -0,0 +1,49 @@
+// Command sabre-lsp is a language server for sabre source files.
+//
+// The server communicates with the editor using the Language Server Protocol
+// over standard input and output. Source is analyzed against a root scope
+// created using sabre.New(). Embedders exposing their own bindings should
+// build a similar command using the lsp package with their root scope.
+//
+// Usage:
+//
+//	sabre-lsp [flags]
+//
+// The flags are:
+//
+//	-disable rule,...
+//	       disable the given lint rules (e.g., unused-local,shadowed-binding)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/lint"
+	"github.com/spy16/sabre/lsp"
+)
+
+var disable = flag.String("disable", "", "comma separated list of lint rules to disable")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: sabre-lsp [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var opts []lsp.Option
+	if strings.TrimSpace(*disable) != "" {
+		opts = append(opts, lsp.WithLintOptions(lint.WithDisabled(strings.Split(*disable, ",")...)))
+	}
+
+	srv := lsp.New(sabre.New(), opts...)
+	if err := srv.Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "sabre-lsp: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "NoChange",
+			a:    "(a)\n(b)\n",
+			b:    "(a)\n(b)\n",
+			want: "",
+		},
+		{
+			name: "Changed",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "SeparateHunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n" +
+				"@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name: "NoTrailingNewline",
+			a:    "(a  )",
+			b:    "(a)\n",
+			want: "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-(a  )\n\\ No newline at end of file\n+(a)\n",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a", "b", tt.a, tt.b); got != tt.want {
+				t.Errorf("unifiedDiff() got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
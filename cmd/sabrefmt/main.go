@@ -0,0 +1,216 @@
+// Command sabrefmt formats sabre source files.
+//
+// Without an explicit path, it processes the standard input. Given a file,
+// it operates on that file; given a directory, it operates on all '.lisp'
+// and '.sabre' files in that directory, recursively. By default, sabrefmt
+// prints the reformatted sources to standard output.
+//
+// Usage:
+//
+//	sabrefmt [flags] [path ...]
+//
+// The flags are:
+//
+//	-l     list files whose formatting differs from sabrefmt's
+//	-w     write result to (source) file instead of stdout
+//	-d     display diffs instead of rewriting files
+//	-indent n
+//	       number of spaces used for indenting bodies (default 2)
+//	-body name=n,...
+//	       additional body style forms with their number of header args
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/format"
+)
+
+var (
+	list   = flag.Bool("l", false, "list files whose formatting differs from sabrefmt's")
+	write  = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	diff   = flag.Bool("d", false, "display diffs instead of rewriting files")
+	indent = flag.Int("indent", format.DefaultIndent, "number of spaces used for indenting bodies")
+	body   = flag.String("body", "", "additional body style forms as name=headerArgs pairs separated by ','")
+)
+
+var extensions = []string{".lisp", ".sabre"}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	opts, err := parseBodyForms(*body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sabrefmt: %v\n", err)
+		os.Exit(2)
+	}
+	f := format.New(append(opts, format.WithIndent(*indent))...)
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "sabrefmt: cannot use -w with standard input")
+			os.Exit(2)
+		}
+
+		if err := processFile(f, "<standard input>", os.Stdin, os.Stdout); err != nil {
+			report(err)
+		}
+		os.Exit(exitCode)
+	}
+
+	for _, path := range flag.Args() {
+		info, err := os.Stat(path)
+		if err != nil {
+			report(err)
+			continue
+		}
+
+		if info.IsDir() {
+			walkDir(f, path)
+		} else if err := processFile(f, path, nil, os.Stdout); err != nil {
+			report(err)
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+var exitCode = 0
+
+func report(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	exitCode = 2
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: sabrefmt [flags] [path ...]\n")
+	flag.PrintDefaults()
+}
+
+func walkDir(f *format.Formatter, root string) {
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			report(err)
+			return nil
+		}
+
+		if info.IsDir() || !isSourceFile(info.Name()) {
+			return nil
+		}
+
+		if err := processFile(f, path, nil, os.Stdout); err != nil {
+			report(err)
+		}
+		return nil
+	})
+}
+
+func isSourceFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	ext := filepath.Ext(name)
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// processFile formats the file and writes the result as configured by the
+// flags. If in is nil, the file is read from filename.
+func processFile(f *format.Formatter, filename string, in io.Reader, out io.Writer) error {
+	var perm os.FileMode = 0644
+	if in == nil {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		in, perm = file, info.Mode().Perm()
+	}
+
+	src, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	res, err := f.Source(src)
+	if err != nil {
+		if readErr, ok := err.(sabre.ReadError); ok {
+			readErr.File = filename
+			return readErr
+		}
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	if bytes.Equal(src, res) {
+		if !*list && !*write && !*diff {
+			_, err = out.Write(res)
+		}
+		return err
+	}
+
+	if *list {
+		fmt.Fprintln(out, filename)
+	}
+
+	if *write {
+		if err := ioutil.WriteFile(filename, res, perm); err != nil {
+			return err
+		}
+	}
+
+	if *diff {
+		fmt.Fprintf(out, "diff -u %s.orig %s\n", filepath.ToSlash(filename), filepath.ToSlash(filename))
+		_, err := io.WriteString(out, unifiedDiff(filename+".orig", filename, string(src), string(res)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if !*list && !*write && !*diff {
+		_, err = out.Write(res)
+	}
+	return err
+}
+
+func parseBodyForms(spec string) ([]format.Option, error) {
+	var opts []format.Option
+	if strings.TrimSpace(spec) == "" {
+		return opts, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.New("-body expects name=headerArgs pairs separated by ','")
+		}
+
+		headerArgs, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid header args for '%s': %v", parts[0], err)
+		}
+		opts = append(opts, format.WithBodyForm(parts[0], headerArgs))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,126 @@
+// Command sabrelint reports problems in sabre source files.
+//
+// Files are analyzed against a root scope created using sabre.New(). Given
+// a directory, all '.lisp' and '.sabre' files in that directory are analyzed
+// recursively. Exit status is 1 if any errors are reported and 2 if files
+// could not be read.
+//
+// Usage:
+//
+//	sabrelint [flags] path ...
+//
+// The flags are:
+//
+//	-errors
+//	       report only errors
+//	-disable rule,...
+//	       disable the given rules (e.g., unused-local,shadowed-binding)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/lint"
+)
+
+var (
+	errorsOnly = flag.Bool("errors", false, "report only errors")
+	disable    = flag.String("disable", "", "comma separated list of rules to disable")
+)
+
+var extensions = []string{".lisp", ".sabre"}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: sabrelint [flags] path ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var opts []lint.Option
+	if strings.TrimSpace(*disable) != "" {
+		opts = append(opts, lint.WithDisabled(strings.Split(*disable, ",")...))
+	}
+	l := lint.New(sabre.New(), opts...)
+
+	exitCode := 0
+	for _, path := range flag.Args() {
+		files, err := sourceFiles(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 2
+			continue
+		}
+
+		for _, file := range files {
+			issues, err := l.LintFile(file)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exitCode = 2
+				continue
+			}
+
+			for _, issue := range issues {
+				if *errorsOnly && issue.Severity != lint.Error {
+					continue
+				}
+
+				fmt.Println(issue)
+				if issue.Severity == lint.Error && exitCode == 0 {
+					exitCode = 1
+				}
+			}
+		}
+	}
+
+	os.Exit(exitCode)
+}
+
+func sourceFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && isSourceFile(info.Name()) {
+			files = append(files, p)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+func isSourceFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	ext := filepath.Ext(name)
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
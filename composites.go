@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync/atomic"
+	"unsafe"
 )
 
 // List represents an list of forms/vals. Evaluating a list leads to a
@@ -12,7 +14,10 @@ type List struct {
 	Values
 	Position
 
-	special *Fn
+	// expanded holds *Values with the result of macro expansion. It is set
+	// atomically since the same list may be evaluated concurrently (e.g.,
+	// body of a function called from multiple goroutines).
+	expanded unsafe.Pointer
 }
 
 // Eval performs an invocation.
@@ -21,16 +26,16 @@ func (lf *List) Eval(scope Scope) (Value, error) {
 		return lf, nil
 	}
 
-	err := lf.parse(scope)
+	values, special, err := lf.parse(scope)
 	if err != nil {
 		return nil, err
 	}
 
-	if lf.special != nil {
-		return lf.special.Invoke(scope, lf.Values[1:]...)
+	if special != nil {
+		return special.Invoke(scope, values[1:]...)
 	}
 
-	target, err := Eval(scope, lf.Values[0])
+	target, err := Eval(scope, values[0])
 	if err != nil {
 		return nil, err
 	}
@@ -42,43 +47,51 @@ func (lf *List) Eval(scope Scope) (Value, error) {
 		)
 	}
 
-	return invokable.Invoke(scope, lf.Values[1:]...)
+	return invokable.Invoke(scope, values[1:]...)
 }
 
 func (lf List) String() string {
 	return containerString(lf.Values, "(", ")", " ")
 }
 
-func (lf *List) parse(scope Scope) error {
+// parse expands the list if it is a macro call and analyzes the forms. The
+// expansion is cached in the list. Returns the forms after expansion and the
+// parsed special form if the list is a special form invocation.
+func (lf *List) parse(scope Scope) (Values, *Fn, error) {
 	if lf.Size() == 0 {
-		return nil
+		return nil, nil, nil
 	}
 
-	form, expanded, err := MacroExpand(scope, lf)
-	if err != nil {
-		return err
-	}
+	values := lf.Values
+	if p := atomic.LoadPointer(&lf.expanded); p != nil {
+		values = *(*Values)(p)
+	} else {
+		form, expanded, err := MacroExpand(scope, lf)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	if expanded {
-		lf.Values = Values{
-			Symbol{Value: "do"},
-			form,
+		if expanded {
+			values = Values{
+				Symbol{Value: "do"},
+				form,
+			}
+			atomic.StorePointer(&lf.expanded, unsafe.Pointer(&values))
 		}
 	}
 
-	special, err := resolveSpecial(scope, lf.First())
+	special, err := resolveSpecial(scope, values.First())
 	if err != nil {
-		return err
+		return nil, nil, err
 	} else if special == nil {
-		return analyzeSeq(scope, lf.Values)
+		return values, nil, analyzeSeq(scope, values)
 	}
 
-	fn, err := special.Parse(scope, lf.Values[1:])
+	fn, err := special.Parse(scope, values[1:])
 	if err != nil {
-		return fmt.Errorf("%s: %v", special.Name, err)
+		return nil, nil, fmt.Errorf("%s: %v", special.Name, err)
 	}
-	lf.special = fn
-	return nil
+	return values, fn, nil
 }
 
 // Vector represents a list of values. Unlike List type, evaluation of
@@ -136,7 +149,9 @@ type Set struct {
 // Eval evaluates each value in the set form and returns the resultant
 // values as new set.
 func (set Set) Eval(scope Scope) (Value, error) {
-	vals, err := evalValueList(scope, set.Uniq())
+	// forms of sets read by the reader are unique already. evaluating the
+	// forms in place retains their slots for locating errors.
+	vals, err := evalValueList(scope, set.Values)
 	if err != nil {
 		return nil, err
 	}
@@ -176,12 +191,12 @@ func (hm *HashMap) Eval(scope Scope) (Value, error) {
 	for k, v := range hm.Data {
 		key, err := k.Eval(scope)
 		if err != nil {
-			return nil, err
+			return nil, newEvalErr(k, err)
 		}
 
 		val, err := v.Eval(scope)
 		if err != nil {
-			return nil, err
+			return nil, newEvalErr(v, err)
 		}
 
 		res.Data[key] = val
@@ -190,12 +205,28 @@ func (hm *HashMap) Eval(scope Scope) (Value, error) {
 	return res, nil
 }
 
-func (hm *HashMap) String() string {
-	var fields []Value
-	for k, v := range hm.Data {
-		fields = append(fields, k, v)
+func (hm *HashMap) String() string { return PrStr(hm) }
+
+// Compare returns true if 'v' is also a hash-map with the same set of keys
+// and equivalent values.
+func (hm *HashMap) Compare(v Value) bool {
+	other, ok := v.(*HashMap)
+	if !ok || other == nil {
+		return false
 	}
-	return containerString(fields, "{", "}", " ")
+
+	if len(hm.Data) != len(other.Data) {
+		return false
+	}
+
+	for k, v1 := range hm.Data {
+		v2, found := other.Data[k]
+		if !found || !Compare(v1, v2) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // Get returns the value associated with the given key if found.
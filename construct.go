@@ -0,0 +1,288 @@
+package sabre
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// construct creates a value of the type using the arguments. See Type.Invoke()
+// for the supported forms.
+func construct(rt reflect.Type, args []Value) (reflect.Value, error) {
+	if len(args) == 1 {
+		if arg := reflectValues(args)[0]; arg.IsValid() && arg.Type() == rt {
+			return arg, nil
+		}
+	}
+
+	if rt.Kind() == reflect.Ptr {
+		elem, err := construct(rt.Elem(), args)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(rt.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	if len(args) == 0 {
+		return reflect.New(rt).Elem(), nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Struct:
+		return constructStruct(rt, args)
+
+	case reflect.Slice, reflect.Array:
+		if len(args) == 1 && isCollection(args[0]) {
+			return convertCollection(rt, args[0])
+		}
+		return convertCollection(rt, Values(args))
+
+	case reflect.Map:
+		hm, err := hashMapArg(args)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return convertCollection(rt, hm)
+
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(args) != 1 {
+			return reflect.Value{}, fmt.Errorf(
+				"call requires exactly 1 argument, got %d", len(args))
+		}
+		return convertScalar(rt, args[0])
+	}
+
+	return reflect.Value{}, fmt.Errorf("type '%s' cannot be initialized with arguments", rt)
+}
+
+// constructStruct initializes the struct using a hash-map of field values
+// or positional values for all the fields. Fields are same as the ones used
+// by StructToMap().
+func constructStruct(rt reflect.Type, args []Value) (reflect.Value, error) {
+	if len(args) == 1 {
+		switch args[0].(type) {
+		case *HashMap, MapView:
+			return convertStruct(rt, args[0])
+		}
+	}
+
+	fields := StructFields(rt)
+	if len(args) != len(fields) {
+		return reflect.Value{}, fmt.Errorf(
+			"expecting a hash-map or %d field values for '%s', got %d arguments",
+			len(fields), rt, len(args))
+	}
+
+	res := reflect.New(rt).Elem()
+	for i, f := range fields {
+		fv := f.Settable(res)
+		c, err := convertArgTo(fv.Type(), reflectValues(args[i : i+1])[0])
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		fv.Set(c)
+	}
+
+	return res, nil
+}
+
+// convertScalar converts the value to the boolean, numeric or string type.
+// Numbers are converted between integer and floating point types with
+// overflow checks and strings are parsed as numbers.
+func convertScalar(rt reflect.Type, v Value) (reflect.Value, error) {
+	res := reflect.New(rt).Elem()
+
+	switch rt.Kind() {
+	case reflect.Bool:
+		b, ok := v.(Bool)
+		if !ok {
+			return res, cannotConvert(v, rt)
+		}
+		res.SetBool(bool(b))
+
+	case reflect.String:
+		switch s := v.(type) {
+		case String:
+			res.SetString(string(s))
+
+		case Keyword:
+			res.SetString(string(s))
+
+		case Symbol:
+			res.SetString(s.Value)
+
+		case Character:
+			res.SetString(string(s))
+
+		case Int64:
+			res.SetString(strconv.FormatInt(int64(s), 10))
+
+		case Float64:
+			res.SetString(strconv.FormatFloat(float64(s), 'g', -1, 64))
+
+		default:
+			return res, cannotConvert(v, rt)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(v, rt)
+		if err != nil {
+			return res, err
+		}
+
+		if res.OverflowFloat(f) {
+			return res, fmt.Errorf("value %s overflows '%s'", v, rt)
+		}
+		res.SetFloat(f)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(v, rt)
+		if err != nil {
+			return res, err
+		}
+
+		if res.OverflowInt(n) {
+			return res, fmt.Errorf("value %d overflows '%s'", n, rt)
+		}
+		res.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toUint(v, rt)
+		if err != nil {
+			return res, err
+		}
+
+		if res.OverflowUint(n) {
+			return res, fmt.Errorf("value %d overflows '%s'", n, rt)
+		}
+		res.SetUint(n)
+	}
+
+	return res, nil
+}
+
+func toInt(v Value, rt reflect.Type) (int64, error) {
+	switch n := v.(type) {
+	case Int64:
+		return int64(n), nil
+
+	case Float64:
+		if err := checkIntegral(n, rt); err != nil {
+			return 0, err
+		}
+		return int64(n), nil
+
+	case Character:
+		return int64(n), nil
+
+	case String:
+		i, err := strconv.ParseInt(string(n), 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", cannotConvert(v, rt), err.(*strconv.NumError).Err)
+		}
+		return i, nil
+	}
+
+	return 0, cannotConvert(v, rt)
+}
+
+func toUint(v Value, rt reflect.Type) (uint64, error) {
+	if f, isFloat := v.(Float64); isFloat {
+		// floats beyond the range of int64 may still fit uint64.
+		if err := checkIntegral(f, rt); err != nil {
+			return 0, err
+		}
+		return uint64(f), nil
+	}
+
+	n, err := toInt(v, rt)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("value %d overflows '%s'", n, rt)
+	}
+	return uint64(n), nil
+}
+
+// checkIntegral returns error if the float is not an integer (including NaN
+// and infinities) or is outside the range of the integer type. The range is
+// checked before converting since converting out of range floats to integers
+// yields arbitrary values.
+func checkIntegral(f Float64, rt reflect.Type) error {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) || v != math.Trunc(v) {
+		return fmt.Errorf("%w: not an integer", cannotConvert(f, rt))
+	}
+
+	min, max := -math.Ldexp(1, rt.Bits()-1), math.Ldexp(1, rt.Bits()-1)
+	if isKind(rt, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64) {
+		min, max = 0, math.Ldexp(1, rt.Bits())
+	}
+
+	if v < min || v >= max {
+		return fmt.Errorf("value %s overflows '%s'", f, rt)
+	}
+	return nil
+}
+
+func toFloat(v Value, rt reflect.Type) (float64, error) {
+	switch n := v.(type) {
+	case Float64:
+		return float64(n), nil
+
+	case Int64:
+		return float64(n), nil
+
+	case String:
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", cannotConvert(v, rt), err.(*strconv.NumError).Err)
+		}
+		return f, nil
+	}
+
+	return 0, cannotConvert(v, rt)
+}
+
+// hashMapArg returns the hash-map from the arguments which must be a single
+// hash-map or key-value pairs.
+func hashMapArg(args []Value) (Value, error) {
+	if len(args) == 1 {
+		switch args[0].(type) {
+		case *HashMap, MapView:
+			return args[0], nil
+		}
+	}
+
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("expecting a hash-map or key-value pairs, got %d arguments", len(args))
+	}
+
+	hm := &HashMap{Data: make(map[Value]Value, len(args)/2)}
+	for i := 0; i < len(args); i += 2 {
+		if err := hm.Set(args[i], args[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return hm, nil
+}
+
+func isCollection(v Value) bool {
+	switch v.(type) {
+	case Vector, *List, Set, Values, SliceView:
+		return true
+	}
+	return false
+}
+
+func cannotConvert(v Value, rt reflect.Type) error {
+	return fmt.Errorf("cannot convert %s of type '%s' to '%s'", PrStr(v), reflect.TypeOf(v), rt)
+}
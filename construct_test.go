@@ -0,0 +1,209 @@
+package sabre_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestType_Invoke(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr string
+	}{
+		{
+			name: "Zero",
+			src:  `(city-name (address))`,
+			want: sabre.String(""),
+		},
+		{
+			name: "StructFromMap",
+			src:  `(city-name (address {:city "Rome" :zip "00100"}))`,
+			want: sabre.String("Rome"),
+		},
+		{
+			name: "StructPositional",
+			src:  `(zip (address "Rome" "00100"))`,
+			want: sabre.String("00100"),
+		},
+		{
+			name: "Pointer",
+			src:  `(city (address-ptr {:city "Lima"}))`,
+			want: sabre.String("Lima"),
+		},
+		{
+			name: "ZeroPointer",
+			src:  `(city (address-ptr))`,
+			want: sabre.String(""),
+		},
+		{
+			name: "Int32",
+			src:  `(int32 10)`,
+			want: sabre.Int64(10),
+		},
+		{
+			name: "IntFromString",
+			src:  `(int32 "0x10")`,
+			want: sabre.Int64(16),
+		},
+		{
+			name: "IntFromFloat",
+			src:  `(int32 -10.0)`,
+			want: sabre.Int64(-10),
+		},
+		{
+			name: "LargeUnsignedFromFloat",
+			src:  `(uint64 1e19)`,
+			want: sabre.ValueOf(uint64(1e19)),
+		},
+		{
+			name: "FloatFromInt",
+			src:  `(float32 2)`,
+			want: sabre.Float64(2),
+		},
+		{
+			name: "StringFromNumber",
+			src:  `(string 10)`,
+			want: sabre.String("10"),
+		},
+		{
+			name: "Slice",
+			src:  `(int-slice 1 2 3)`,
+			want: sabre.Vector{Values: []sabre.Value{
+				sabre.Int64(1), sabre.Int64(2), sabre.Int64(3),
+			}},
+		},
+		{
+			name: "SliceFromSeq",
+			src:  `(int-slice [1 2])`,
+			want: sabre.Vector{Values: []sabre.Value{sabre.Int64(1), sabre.Int64(2)}},
+		},
+		{
+			name: "Map",
+			src:  `(:b (string-map "a" 1 "b" 2))`,
+			want: sabre.Int64(2),
+		},
+		{
+			name: "MapFromHashMap",
+			src:  `(:a (string-map {"a" 1}))`,
+			want: sabre.Int64(1),
+		},
+		{
+			name:    "Overflow",
+			src:     `(int8 300)`,
+			wantErr: "value 300 overflows 'int8'",
+		},
+		{
+			name:    "NegativeUnsigned",
+			src:     `(uint -1)`,
+			wantErr: "value -1 overflows 'uint'",
+		},
+		{
+			name:    "NonIntegralFloat",
+			src:     `(int32 10.7)`,
+			wantErr: "cannot convert 10.7 of type 'sabre.Float64' to 'int32': not an integer",
+		},
+		{
+			name:    "InfToInt",
+			src:     `(int32 ##Inf)`,
+			wantErr: "cannot convert ##Inf of type 'sabre.Float64' to 'int32': not an integer",
+		},
+		{
+			name:    "NaNToUnsigned",
+			src:     `(uint ##NaN)`,
+			wantErr: "cannot convert ##NaN of type 'sabre.Float64' to 'uint': not an integer",
+		},
+		{
+			name:    "FloatOverflow",
+			src:     `(int8 1e20)`,
+			wantErr: "value 1e+20 overflows 'int8'",
+		},
+		{
+			name:    "FloatOverflowUnsigned",
+			src:     `(uint64 2e19)`,
+			wantErr: "value 2e+19 overflows 'uint64'",
+		},
+		{
+			name:    "NegativeFloatUnsigned",
+			src:     `(uint -1.0)`,
+			wantErr: "value -1.0 overflows 'uint'",
+		},
+		{
+			name:    "UnparsableString",
+			src:     `(int32 "ten")`,
+			wantErr: "cannot convert \"ten\" of type 'sabre.String' to 'int32': invalid syntax",
+		},
+		{
+			name:    "UnknownField",
+			src:     `(address {:street "Main"})`,
+			wantErr: "unknown field 'street' in 'sabre_test.address'",
+		},
+		{
+			name:    "UnconvertibleField",
+			src:     `(address 10 "00100")`,
+			wantErr: "field 'city': value of type 'sabre.Int64' cannot be converted to 'string'",
+		},
+		{
+			name:    "FieldCount",
+			src:     `(address "Rome")`,
+			wantErr: "expecting a hash-map or 2 field values for 'sabre_test.address', got 1 arguments",
+		},
+		{
+			name:    "UnconvertibleItem",
+			src:     `(int-slice 1 "two")`,
+			wantErr: "item 1: value of type 'sabre.String' cannot be converted to 'int'",
+		},
+		{
+			name:    "OddMapArgs",
+			src:     `(string-map "a")`,
+			wantErr: "expecting a hash-map or key-value pairs, got 1 arguments",
+		},
+		{
+			name:    "Func",
+			src:     `(func-type)`,
+			wantErr: "cannot be initialized",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("address", reflect.TypeOf(address{}))
+			scope.BindGo("address-ptr", reflect.TypeOf(&address{}))
+			scope.BindGo("int8", reflect.TypeOf(int8(0)))
+			scope.BindGo("int32", reflect.TypeOf(int32(0)))
+			scope.BindGo("uint", reflect.TypeOf(uint(0)))
+			scope.BindGo("uint64", reflect.TypeOf(uint64(0)))
+			scope.BindGo("float32", reflect.TypeOf(float32(0)))
+			scope.BindGo("string", reflect.TypeOf(""))
+			scope.BindGo("int-slice", reflect.TypeOf([]int{}))
+			scope.BindGo("string-map", reflect.TypeOf(map[string]int{}))
+			scope.BindGo("func-type", reflect.TypeOf(func() {}))
+			scope.BindGo("city-name", func(a address) string { return a.City })
+			scope.BindGo("zip", func(a address) string { return a.Zip })
+			scope.BindGo("city", func(a *address) string { return a.City })
+
+			got, err := sabre.ReadEvalStr(scope, tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Eval() expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+
+			if !sabre.Compare(tt.want, got) {
+				t.Errorf("Eval() want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sabre
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithContext returns a scope that delegates to the given scope and fails
+// to resolve any symbol once the context is done. Since evaluation of almost
+// every form resolves symbols, this stops an evaluation against the returned
+// scope (or scopes derived from it) soon after the context is cancelled. Go
+// functions blocked in a call are not interrupted.
+func WithContext(ctx context.Context, scope Scope) Scope {
+	return &contextScope{Scope: scope, ctx: ctx}
+}
+
+// ContextOf returns the context of the nearest scope in the parent chain that
+// was created using WithContext(). Returns context.Background() if there is
+// no such scope.
+func ContextOf(scope Scope) context.Context {
+	found := findScope(scope, func(s Scope) bool {
+		_, ok := s.(*contextScope)
+		return ok
+	})
+	if found != nil {
+		return found.(*contextScope).ctx
+	}
+
+	return context.Background()
+}
+
+type contextScope struct {
+	Scope
+	ctx context.Context
+}
+
+func (cs *contextScope) Resolve(symbol string) (Value, error) {
+	if err := cs.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("evaluation cancelled: %w", err)
+	}
+
+	return cs.Scope.Resolve(symbol)
+}
+
+func (cs *contextScope) unwrap() Scope { return cs.Scope }
+
+// scopeWrapper is implemented by scopes that delegate to another scope and
+// carry evaluation settings (e.g., scopes created using WithContext()).
+type scopeWrapper interface {
+	unwrap() Scope
+}
+
+// findScope walks the parent chain of the scope and returns the first scope
+// matching the predicate. Since a wrapper shares the parent of the scope it
+// wraps, wrapped scopes are checked before moving to the parent.
+func findScope(scope Scope, match func(s Scope) bool) Scope {
+	for s := scope; s != nil; s = s.Parent() {
+		for {
+			if match(s) {
+				return s
+			}
+
+			w, ok := s.(scopeWrapper)
+			if !ok {
+				break
+			}
+			s = w.unwrap()
+		}
+	}
+
+	return nil
+}
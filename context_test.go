@@ -0,0 +1,39 @@
+package sabre_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestWithContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	root := sabre.New()
+	scope := sabre.WithContext(ctx, root)
+
+	if _, err := sabre.ReadEvalStr(scope, "(def a 1) a"); err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	if _, err := root.Resolve("a"); err != nil {
+		t.Errorf("expected def to bind in the wrapped scope: %v", err)
+	}
+
+	if got := sabre.ContextOf(sabre.NewScope(scope)); got != ctx {
+		t.Errorf("ContextOf() expected context of the parent scope")
+	}
+
+	cancel()
+	_, err := sabre.ReadEvalStr(scope, "a")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ReadEvalStr() expected context.Canceled, got %v", err)
+	}
+
+	if got := sabre.ContextOf(root); got != context.Background() {
+		t.Errorf("ContextOf() expected background context, got %v", got)
+	}
+}
@@ -0,0 +1,327 @@
+package sabre
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// View returns a lazy view of the Go slice, array or map. Unlike ValueOf()
+// which converts all the items upfront, items of a view are converted when
+// accessed. Views of slices and arrays implement Seq and views of maps
+// support keyword lookup similar to HashMap. View is same as ValueOf() for
+// values of other types. Bound functions can avoid copying large results by
+// returning the view as Value (e.g., 'func() sabre.Value').
+func View(v interface{}) Value {
+	if v == nil {
+		return Nil{}
+	}
+
+	if val, isValue := v.(Value); isValue {
+		return val
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		return SliceView{rv: rv}
+
+	case reflect.Array:
+		arr := reflect.New(rv.Type()).Elem()
+		arr.Set(rv)
+		return SliceView{rv: arr.Slice(0, arr.Len())}
+
+	case reflect.Map:
+		return MapView{rv: rv}
+
+	default:
+		return ValueOf(v)
+	}
+}
+
+// SliceView is a lazy view of a Go slice or array. See View().
+type SliceView struct {
+	rv reflect.Value
+}
+
+// Eval returns the view itself.
+func (sv SliceView) Eval(_ Scope) (Value, error) { return sv, nil }
+
+func (sv SliceView) String() string { return Vector{Values: sv.realize()}.String() }
+
+// Size returns the number of items in the slice.
+func (sv SliceView) Size() int { return sv.rv.Len() }
+
+// First returns the first item of the slice or nil if the slice is empty.
+func (sv SliceView) First() Value {
+	if sv.rv.Len() == 0 {
+		return nil
+	}
+	return View(sv.rv.Index(0).Interface())
+}
+
+// Next returns a view of the slice excluding the first item.
+func (sv SliceView) Next() Seq {
+	if sv.rv.Len() <= 1 {
+		return nil
+	}
+	return SliceView{rv: sv.rv.Slice(1, sv.rv.Len())}
+}
+
+// Cons returns a new sequence with the value added to the beginning of the
+// items of the slice. The slice is not modified.
+func (sv SliceView) Cons(v Value) Seq { return sv.realize().Cons(v) }
+
+// Conj returns a new sequence with the values appended to the items of the
+// slice. The slice is not modified.
+func (sv SliceView) Conj(vals ...Value) Seq { return sv.realize().Conj(vals...) }
+
+// Invoke performs an index lookup similar to Vector.
+func (sv SliceView) Invoke(scope Scope, args ...Value) (Value, error) {
+	vals, err := evalValueList(scope, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("call requires exactly 1 argument, got %d", len(vals))
+	}
+
+	index, isInt := vals[0].(Int64)
+	if !isInt {
+		return nil, fmt.Errorf("key must be integer")
+	}
+
+	if index < 0 || int(index) >= sv.rv.Len() {
+		return nil, fmt.Errorf("index out of bounds")
+	}
+
+	return View(sv.rv.Index(int(index)).Interface()), nil
+}
+
+// Compare returns true if 'v' is a sequence with equivalent items.
+func (sv SliceView) Compare(v Value) bool { return sv.realize().Compare(v) }
+
+func (sv SliceView) goValue() reflect.Value { return sv.rv }
+
+func (sv SliceView) realize() Values {
+	vals := make(Values, sv.rv.Len())
+	for i := range vals {
+		vals[i] = View(sv.rv.Index(i).Interface())
+	}
+	return vals
+}
+
+// MapView is a lazy view of a Go map. See View().
+type MapView struct {
+	rv reflect.Value
+}
+
+// Eval returns the view itself.
+func (mv MapView) Eval(_ Scope) (Value, error) { return mv, nil }
+
+func (mv MapView) String() string { return mv.realize().String() }
+
+// Size returns the number of entries in the map.
+func (mv MapView) Size() int { return mv.rv.Len() }
+
+// Get returns the value associated with the given key if found. Returns def
+// otherwise.
+func (mv MapView) Get(key Value, def Value) Value {
+	k, ok := goMapKey(mv.rv.Type().Key(), key)
+	if !ok {
+		return def
+	}
+
+	v := mv.rv.MapIndex(k)
+	if !v.IsValid() {
+		return def
+	}
+	return View(v.Interface())
+}
+
+// Keys returns all the keys in the map.
+func (mv MapView) Keys() Values {
+	var res Values
+	for _, k := range mv.rv.MapKeys() {
+		res = append(res, mapKeyOf(k))
+	}
+	return res
+}
+
+// Values returns all the values in the map.
+func (mv MapView) Values() Values {
+	var res Values
+	iter := mv.rv.MapRange()
+	for iter.Next() {
+		res = append(res, View(iter.Value().Interface()))
+	}
+	return res
+}
+
+// Compare returns true if 'v' is a hash-map or map view with the same set
+// of keys and equivalent values.
+func (mv MapView) Compare(v Value) bool {
+	if other, ok := v.(MapView); ok {
+		v = other.realize()
+	}
+	return mv.realize().Compare(v)
+}
+
+func (mv MapView) goValue() reflect.Value { return mv.rv }
+
+func (mv MapView) realize() *HashMap {
+	hm := &HashMap{Data: make(map[Value]Value, mv.rv.Len())}
+	iter := mv.rv.MapRange()
+	for iter.Next() {
+		hm.Data[mapKeyOf(iter.Key())] = View(iter.Value().Interface())
+	}
+	return hm
+}
+
+// goValuer is implemented by values wrapping a Go value which can be passed
+// to Go functions expecting the wrapped type.
+type goValuer interface {
+	goValue() reflect.Value
+}
+
+// getter is implemented by map like values that support keyword lookup.
+type getter interface {
+	Get(key Value, def Value) Value
+}
+
+func vectorOf(rv reflect.Value, structs bool) Value {
+	vals := make(Values, rv.Len())
+	for i := range vals {
+		vals[i] = valueOf(rv.Index(i).Interface(), structs)
+	}
+	return Vector{Values: vals}
+}
+
+func hashMapOf(rv reflect.Value, structs bool) Value {
+	hm := &HashMap{Data: make(map[Value]Value, rv.Len())}
+	iter := rv.MapRange()
+	for iter.Next() {
+		hm.Data[mapKeyOf(iter.Key())] = valueOf(iter.Value().Interface(), structs)
+	}
+	return hm
+}
+
+// mapKeyOf converts the Go map key to a Value. String keys are converted to
+// keywords to support keyword lookup. Keys that cannot be used as HashMap
+// keys after conversion are wrapped using Any.
+func mapKeyOf(rv reflect.Value) Value {
+	if rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.String {
+		return Keyword(rv.String())
+	}
+
+	key := ValueOf(rv.Interface())
+	if !reflect.TypeOf(key).Comparable() {
+		return Any{V: rv}
+	}
+	return key
+}
+
+// goMapKey converts the key to the key type of a Go map. Keywords and strings
+// are converted only to string (or interface) keys.
+func goMapKey(rt reflect.Type, key Value) (reflect.Value, bool) {
+	var name string
+	switch k := key.(type) {
+	case Keyword:
+		name = string(k)
+
+	case String:
+		name = string(k)
+
+	default:
+		if rt.Kind() == reflect.String {
+			return reflect.Value{}, false
+		}
+
+		rv, err := convertArgTo(rt, reflectValues([]Value{key})[0])
+		return rv, err == nil
+	}
+
+	if !isKind(rt, reflect.String, reflect.Interface) {
+		return reflect.Value{}, false
+	}
+
+	rv, err := convertArgTo(rt, reflect.ValueOf(name))
+	return rv, err == nil
+}
+
+// convertCollection converts sequences to Go slices or arrays and hash-maps
+// to Go maps of the given type.
+func convertCollection(rt reflect.Type, v Value) (reflect.Value, error) {
+	if _, isNil := v.(Nil); isNil && rt.Kind() != reflect.Array {
+		return reflect.Zero(rt), nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		seq, ok := v.(Seq)
+		if !ok {
+			break
+		}
+
+		var items []Value
+		for ; !isEmptySeq(seq); seq = seq.Next() {
+			items = append(items, seq.First())
+		}
+
+		var res reflect.Value
+		if rt.Kind() == reflect.Array {
+			if len(items) != rt.Len() {
+				return reflect.Value{}, fmt.Errorf(
+					"expecting %d items for '%s', got %d", rt.Len(), rt, len(items))
+			}
+			res = reflect.New(rt).Elem()
+		} else {
+			res = reflect.MakeSlice(rt, len(items), len(items))
+		}
+
+		for i, item := range items {
+			c, err := convertArgTo(rt.Elem(), reflectValues([]Value{item})[0])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("item %d: %w", i, err)
+			}
+			res.Index(i).Set(c)
+		}
+		return res, nil
+
+	case reflect.Map:
+		var entries map[Value]Value
+		switch m := v.(type) {
+		case *HashMap:
+			entries = m.Data
+
+		case MapView:
+			entries = m.realize().Data
+
+		default:
+			return reflect.Value{}, fmt.Errorf(
+				"value of type '%s' cannot be converted to '%s'", reflect.TypeOf(v), rt)
+		}
+
+		res := reflect.MakeMapWithSize(rt, len(entries))
+		for k, val := range entries {
+			key, err := convertArgTo(rt.Key(), reflectValues([]Value{k})[0])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %s: %w", k, err)
+			}
+
+			elem, err := convertArgTo(rt.Elem(), reflectValues([]Value{val})[0])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("value of key %s: %w", k, err)
+			}
+			res.SetMapIndex(key, elem)
+		}
+		return res, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf(
+		"value of type '%s' cannot be converted to '%s'", reflect.TypeOf(v), rt)
+}
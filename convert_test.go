@@ -0,0 +1,221 @@
+package sabre_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr string
+	}{
+		{
+			name: "SliceResult",
+			src:  `(first (split "a,b" ","))`,
+			want: sabre.String("a"),
+		},
+		{
+			name: "MapResult",
+			src:  `(:b (counts "a b b"))`,
+			want: sabre.Int64(2),
+		},
+		{
+			name: "SliceArg",
+			src:  `(sum [1 2 3])`,
+			want: sabre.Int64(6),
+		},
+		{
+			name: "ListArg",
+			src:  `(sum '(1 2))`,
+			want: sabre.Int64(3),
+		},
+		{
+			name: "NilSliceArg",
+			src:  `(sum nil)`,
+			want: sabre.Int64(0),
+		},
+		{
+			name: "ArrayArg",
+			src:  `(first-of-pair [1 2])`,
+			want: sabre.Int64(1),
+		},
+		{
+			name:    "ArrayArgLength",
+			src:     `(first-of-pair [1 2 3])`,
+			wantErr: "expecting 2 items for '[2]int', got 3",
+		},
+		{
+			name: "NestedArg",
+			src:  `(total {:a [1 2] :b [3]})`,
+			want: sabre.Int64(6),
+		},
+		{
+			name:    "UnconvertibleItem",
+			src:     `(sum [1 "two"])`,
+			wantErr: "item 1: value of type 'sabre.String' cannot be converted to 'int'",
+		},
+		{
+			name:    "UnconvertibleValue",
+			src:     `(total {:a ["x"]})`,
+			wantErr: "value of key :a: item 0",
+		},
+		{
+			name: "RoundTrip",
+			src:  `(sum (ints 3))`,
+			want: sabre.Int64(3),
+		},
+		{
+			name: "BytesResult",
+			src:  `(bytes "héllo")`,
+			want: sabre.String("héllo"),
+		},
+		{
+			name: "BytesArg",
+			src:  `(byte-count (bytes "ab"))`,
+			want: sabre.Int64(2),
+		},
+		{
+			name: "SliceView",
+			src:  `[(first lazy) (lazy 1) (first (next lazy))]`,
+			want: sabre.Vector{Values: []sabre.Value{
+				sabre.Int64(1), sabre.Int64(2), sabre.Int64(2),
+			}},
+		},
+		{
+			name: "SliceViewArg",
+			src:  `(sum lazy)`,
+			want: sabre.Int64(6),
+		},
+		{
+			name: "MapView",
+			src:  `[(:a lazy-map) (:z lazy-map :none)]`,
+			want: sabre.Vector{Values: []sabre.Value{
+				sabre.Vector{Values: []sabre.Value{sabre.Int64(1)}},
+				sabre.Keyword("none"),
+			}},
+		},
+		{
+			name: "MapViewArg",
+			src:  `(total lazy-map)`,
+			want: sabre.Int64(1),
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("first", func(s sabre.Seq) sabre.Value { return s.First() })
+			scope.BindGo("next", func(s sabre.Seq) sabre.Value { return s.Next() })
+			scope.BindGo("split", strings.Split)
+			scope.BindGo("counts", func(s string) map[string]int {
+				counts := map[string]int{}
+				for _, f := range strings.Fields(s) {
+					counts[f]++
+				}
+				return counts
+			})
+			scope.BindGo("sum", func(nums []int) int {
+				total := 0
+				for _, n := range nums {
+					total += n
+				}
+				return total
+			})
+			scope.BindGo("bytes", func(s string) []byte { return []byte(s) })
+			scope.BindGo("byte-count", func(b []byte) int { return len(b) })
+			scope.BindGo("first-of-pair", func(pair [2]int) int { return pair[0] })
+			scope.BindGo("total", func(m map[string][]int) int {
+				total := 0
+				for _, nums := range m {
+					for _, n := range nums {
+						total += n
+					}
+				}
+				return total
+			})
+			scope.BindGo("ints", func(n int) []int {
+				nums := make([]int, n)
+				for i := range nums {
+					nums[i] = i
+				}
+				return nums
+			})
+			scope.Bind("lazy", sabre.View([]int{1, 2, 3}))
+			scope.Bind("lazy-map", sabre.View(map[string][]int{"a": {1}}))
+
+			got, err := sabre.ReadEvalStr(scope, tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Eval() expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+
+			if !sabre.Compare(tt.want, got) {
+				t.Errorf("Eval() want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestView(t *testing.T) {
+	t.Parallel()
+
+	items := []int{1, 2}
+	view := sabre.View(items).(sabre.SliceView)
+
+	items[0] = 10
+	if got := view.First(); got != sabre.Int64(10) {
+		t.Errorf("expected view to reflect changes to the slice, got %s", got)
+	}
+
+	if got := view.String(); got != "[10 2]" {
+		t.Errorf("String() expected [10 2], got %s", got)
+	}
+
+	if !sabre.Compare(view, sabre.Vector{Values: []sabre.Value{sabre.Int64(10), sabre.Int64(2)}}) {
+		t.Errorf("expected view to be equivalent to vector")
+	}
+
+	arr := sabre.View([2]string{"a", "b"}).(sabre.SliceView)
+	if arr.Size() != 2 || arr.Next().First() != sabre.String("b") {
+		t.Errorf("expected view of array, got %s", arr)
+	}
+
+	mv := sabre.View(map[int]string{1: "one"}).(sabre.MapView)
+	if got := mv.Get(sabre.Int64(1), sabre.Nil{}); got != sabre.String("one") {
+		t.Errorf("Get() expected \"one\", got %s", got)
+	}
+
+	if got := mv.Get(sabre.Keyword("1"), sabre.Nil{}); got != (sabre.Nil{}) {
+		t.Errorf("Get() expected nil for keyword key, got %s", got)
+	}
+
+	scope := sabre.New()
+	scope.BindGo("items", func() sabre.Value { return sabre.View(items) })
+	got, err := sabre.ReadEvalStr(scope, "(items)")
+	if err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	items[1] = 20
+	if sv, ok := got.(sabre.SliceView); !ok || sv.Next().First() != sabre.Int64(20) {
+		t.Errorf("expected bound function to return the view, got %s", got)
+	}
+
+	if got := sabre.View(10); got != sabre.Int64(10) {
+		t.Errorf("View() expected ValueOf for non-collections, got %s", reflect.TypeOf(got))
+	}
+}
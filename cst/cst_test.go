@@ -0,0 +1,189 @@
+package cst_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/cst"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	table := []string{
+		"",
+		"  \n\t,, ",
+		"; just a comment",
+		"(def x 10) ; trailing comment\n",
+		"(fn* [a b]\n  ;; doc\n  (+ a b))\n",
+		"'x '(1 2) `(a ~b) ' ; sugar with comment\n y",
+		"#_ (ignored) [1 #_2 3]",
+		"#{1 2} #(+ % 1) #\"a\\\"b\\d\" #'foo",
+		"#inst \"2020-01-01\" #foo/bar [1]",
+		"{:a 1, :b \\space} \\( \\u03bb",
+		"\"str with \\\"escapes\\\" and ; not a comment\"",
+		"-10 +5 - + 1.5e3 0x1F 2r101 ##Inf ##-Inf ##NaN",
+		"a:b #",
+		"λ → (unicode \"🧠\")",
+	}
+
+	for _, src := range table {
+		t.Run(src, func(t *testing.T) {
+			root, err := cst.Parse(src)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if got := root.String(); got != src {
+				t.Errorf("String() got = %q, want = %q", got, src)
+			}
+
+			if root.Span.End.Offset != len(src) {
+				t.Errorf("Span.End.Offset got = %d, want = %d", root.Span.End.Offset, len(src))
+			}
+		})
+	}
+}
+
+func TestParse_Structure(t *testing.T) {
+	t.Parallel()
+
+	root, err := cst.Parse("(foo 'x ; c\n #_ y [1 \"s\"])")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	want := "Root[List(Symbol Whitespace Quote[Symbol] Whitespace Comment Whitespace " +
+		"Discard[Whitespace Symbol] Whitespace Vector(Number Whitespace String))]"
+	if got := describe(root); got != want {
+		t.Errorf("tree got = %s\nwant = %s", got, want)
+	}
+
+	list := root.Children[0]
+	if forms := list.Forms(); len(forms) != 3 {
+		t.Errorf("Forms() got %d forms, want 3", len(forms))
+	}
+
+	vec := list.Children[len(list.Children)-1]
+	wantSpan := sabre.Span{
+		Start: sabre.Location{Offset: 18, Line: 2, Column: 7},
+		End:   sabre.Location{Offset: 25, Line: 2, Column: 14},
+	}
+	if vec.Span != wantSpan {
+		t.Errorf("Span got = %s, want = %s", vec.Span, wantSpan)
+	}
+}
+
+func TestNode_Value(t *testing.T) {
+	t.Parallel()
+
+	root, err := cst.Parse("'x #_ 1 [1 2]")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	quote, err := root.Children[0].Value()
+	if err != nil {
+		t.Fatalf("Value() unexpected error: %v", err)
+	}
+
+	want := &sabre.List{Values: sabre.Values{sabre.Symbol{Value: "quote"}, sabre.Symbol{Value: "x"}}}
+	if !sabre.Compare(quote, want) {
+		t.Errorf("Value() got = %s, want = %s", quote, want)
+	}
+
+	if _, err := root.Children[2].Value(); err != sabre.ErrSkip {
+		t.Errorf("Value() of discard expected ErrSkip, got %v", err)
+	}
+
+	mod, err := root.Value()
+	if err != nil {
+		t.Fatalf("Value() unexpected error: %v", err)
+	}
+
+	if got := len(mod.(sabre.Module)); got != 2 {
+		t.Errorf("Value() of root got %d forms, want 2", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		src   string
+		isEOF bool
+	}{
+		{src: "(foo", isEOF: true},
+		{src: "[1 2", isEOF: true},
+		{src: "\"unterminated", isEOF: true},
+		{src: "'", isEOF: true},
+		{src: "#_", isEOF: true},
+		{src: "(foo]"},
+		{src: ")"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := cst.Parse(tt.src)
+			if err == nil {
+				t.Fatalf("Parse() expected error, got nil")
+			}
+
+			if _, ok := err.(sabre.ReadError); !ok {
+				t.Errorf("Parse() expected ReadError, got %#v", err)
+			}
+
+			if errors.Is(err, sabre.ErrEOF) != tt.isEOF {
+				t.Errorf("Parse() error = %v, want EOF = %t", err, tt.isEOF)
+			}
+		})
+	}
+}
+
+func TestReader_SetMacro(t *testing.T) {
+	t.Parallel()
+
+	rd := cst.NewReader(strings.NewReader("@atom #?(:clj 1)"))
+	rd.SetMacro('@', func(rd *cst.Reader, init rune) (*cst.Node, error) {
+		return rd.Prefixed(cst.Unquote, string(init))
+	}, false)
+	rd.SetMacro('?', func(rd *cst.Reader, init rune) (*cst.Node, error) {
+		if _, err := rd.NextRune(); err != nil {
+			return nil, err
+		}
+		return rd.Container(cst.List, "?(", ')')
+	}, true)
+
+	root, err := rd.All()
+	if err != nil {
+		t.Fatalf("All() unexpected error: %v", err)
+	}
+
+	if got, want := describe(root), "Root[Unquote[Symbol] Whitespace List(Keyword Whitespace Number)]"; got != want {
+		t.Errorf("tree got = %s, want = %s", got, want)
+	}
+
+	if got := root.String(); got != "@atom #?(:clj 1)" {
+		t.Errorf("String() got = %q", got)
+	}
+}
+
+func describe(n *cst.Node) string {
+	if len(n.Children) == 0 && !n.IsContainer() {
+		return n.Kind.String()
+	}
+
+	var parts []string
+	for _, child := range n.Children {
+		parts = append(parts, describe(child))
+	}
+
+	open, end := "[", "]"
+	if n.IsContainer() {
+		open, end = "(", ")"
+	}
+
+	return n.Kind.String() + open + strings.Join(parts, " ") + end
+}
@@ -0,0 +1,149 @@
+// Package cst provides a concrete syntax tree reader for sabre source. Unlike
+// sabre.Reader, the tree produced retains comments, whitespace, discarded
+// forms and reader macro sugar (e.g., 'x instead of (quote x)) so that the
+// exact original text can be reproduced. This is useful for tools that need
+// to rewrite source while retaining formatting (e.g., formatters).
+package cst
+
+import (
+	"io"
+	"strings"
+
+	"github.com/spy16/sabre"
+)
+
+// Kind represents the type of a node in the syntax tree.
+type Kind int
+
+// Kinds of nodes produced by the default read table.
+const (
+	Root Kind = iota
+	Whitespace
+	Comment
+	Symbol
+	Keyword
+	Number
+	String
+	Character
+	Regex
+	List
+	Vector
+	HashMap
+	Set
+	AnonFn
+	Quote
+	SyntaxQuote
+	Unquote
+	VarQuote
+	Discard
+	Tagged
+)
+
+var kindNames = [...]string{
+	Root:        "Root",
+	Whitespace:  "Whitespace",
+	Comment:     "Comment",
+	Symbol:      "Symbol",
+	Keyword:     "Keyword",
+	Number:      "Number",
+	String:      "String",
+	Character:   "Character",
+	Regex:       "Regex",
+	List:        "List",
+	Vector:      "Vector",
+	HashMap:     "HashMap",
+	Set:         "Set",
+	AnonFn:      "AnonFn",
+	Quote:       "Quote",
+	SyntaxQuote: "SyntaxQuote",
+	Unquote:     "Unquote",
+	VarQuote:    "VarQuote",
+	Discard:     "Discard",
+	Tagged:      "Tagged",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Unknown"
+	}
+	return kindNames[k]
+}
+
+// Node represents a node in the concrete syntax tree. For leaf nodes (e.g.,
+// symbols, comments) Text is the complete source text of the node. For
+// containers (e.g., lists) Text and Close are the opening and closing
+// delimiters and for prefixed forms (e.g., quote, tagged literals) Text is
+// the prefix ("'", "#inst" etc.) and Children contain the prefixed form along
+// with any whitespace or comments preceding it.
+type Node struct {
+	Kind     Kind
+	Text     string
+	Close    string
+	Children []*Node
+	Span     sabre.Span
+}
+
+// IsTrivia returns true if the node has no effect on the forms read by the
+// sabre reader (i.e., whitespace, comments and discarded forms).
+func (n *Node) IsTrivia() bool {
+	return n.Kind == Whitespace || n.Kind == Comment || n.Kind == Discard
+}
+
+// IsContainer returns true if the node is a delimited collection of nodes.
+func (n *Node) IsContainer() bool {
+	switch n.Kind {
+	case List, Vector, HashMap, Set, AnonFn:
+		return true
+	}
+	return false
+}
+
+// Forms returns the child nodes that are not trivia.
+func (n *Node) Forms() []*Node {
+	var forms []*Node
+	for _, child := range n.Children {
+		if !child.IsTrivia() {
+			forms = append(forms, child)
+		}
+	}
+	return forms
+}
+
+// Value reads the source text of the node using sabre.Reader and returns the
+// form. For root nodes, a sabre.Module is returned. Returns sabre.ErrSkip for
+// trivia nodes.
+func (n *Node) Value() (sabre.Value, error) {
+	if n.IsTrivia() {
+		return nil, sabre.ErrSkip
+	}
+
+	rd := sabre.NewReader(strings.NewReader(n.String()))
+	rd.File = n.Span.File
+	if n.Kind == Root {
+		return rd.All()
+	}
+	return rd.One()
+}
+
+// WriteTo writes the exact source text of the node to w.
+func (n *Node) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	n.write(&sb)
+
+	written, err := io.WriteString(w, sb.String())
+	return int64(written), err
+}
+
+func (n *Node) String() string {
+	var sb strings.Builder
+	n.write(&sb)
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder) {
+	sb.WriteString(n.Text)
+	for _, child := range n.Children {
+		child.write(sb)
+	}
+	sb.WriteString(n.Close)
+}
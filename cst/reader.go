@@ -0,0 +1,485 @@
+package cst
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spy16/sabre"
+)
+
+const dispatchTrigger = '#'
+
+// Parse reads the entire source and returns the root node of the tree.
+func Parse(src string) (*Node, error) {
+	return NewReader(strings.NewReader(src)).All()
+}
+
+// NewReader returns a syntax tree reader which reads from r. The default read
+// table mirrors the read table of sabre.Reader. Reader behavior can be
+// customized by using SetMacro to override or remove entries.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		rs:       bufio.NewReader(r),
+		loc:      sabre.Location{Line: 1, Column: 1},
+		macros:   defaultReadTable(),
+		dispatch: defaultDispatchTable(),
+	}
+}
+
+// Macro implementations can be plugged into the Reader to read custom syntax.
+// Macro is invoked with the trigger rune already consumed and must consume
+// the rest of the node. Text of nodes returned by dispatch macros is prefixed
+// with '#' by the reader.
+type Macro func(rd *Reader, init rune) (*Node, error)
+
+// Reader reads a stream into a concrete syntax tree.
+type Reader struct {
+	File string
+
+	rs       io.RuneReader
+	buf      []rune
+	loc      sabre.Location
+	prev     sabre.Location
+	macros   map[rune]Macro
+	dispatch map[rune]Macro
+}
+
+// All reads nodes until EOF and returns a root node containing all of them.
+func (rd *Reader) All() (*Node, error) {
+	root := &Node{Kind: Root}
+	start := rd.loc
+
+	for {
+		node, err := rd.One()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		root.Children = append(root.Children, node)
+	}
+
+	root.Span = rd.span(start)
+	return root, nil
+}
+
+// One reads the next node from the stream. Unlike sabre.Reader, whitespace,
+// comments and discarded forms are returned as nodes. Except io.EOF, all
+// errors are wrapped with sabre.ReadError.
+func (rd *Reader) One() (*Node, error) {
+	node, err := rd.readOne()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+
+		return nil, sabre.ReadError{
+			Cause: err,
+			Position: sabre.Position{
+				File:   rd.File,
+				Line:   rd.loc.Line,
+				Column: rd.loc.Column - 1,
+			},
+		}
+	}
+
+	return node, nil
+}
+
+// SetMacro sets the given macro as the handler for init rune in the read
+// table. If the macro is nil, the entry is removed. isDispatch decides if
+// the macro takes effect only after a '#' sign.
+func (rd *Reader) SetMacro(init rune, macro Macro, isDispatch bool) {
+	table := rd.macros
+	if isDispatch {
+		table = rd.dispatch
+	}
+
+	if macro == nil {
+		delete(table, init)
+		return
+	}
+	table[init] = macro
+}
+
+// IsTerminal returns true if the rune should terminate a token. Read table
+// trigger runes and all space characters including "," are terminal.
+func (rd *Reader) IsTerminal(r rune) bool {
+	if isSpace(r) {
+		return true
+	}
+
+	_, found := rd.macros[r]
+	return found
+}
+
+// NextRune returns next rune from the stream and advances the stream.
+func (rd *Reader) NextRune() (rune, error) {
+	var r rune
+	if len(rd.buf) > 0 {
+		r = rd.buf[0]
+		rd.buf = rd.buf[1:]
+	} else {
+		temp, _, err := rd.rs.ReadRune()
+		if err != nil {
+			return -1, err
+		}
+		r = temp
+	}
+
+	rd.prev = rd.loc
+	rd.loc.Offset += utf8.RuneLen(r)
+	if r == '\n' {
+		rd.loc.Line++
+		rd.loc.Column = 1
+	} else {
+		rd.loc.Column++
+	}
+
+	return r, nil
+}
+
+// Unread returns the last rune read using NextRune back to the stream. Only
+// one rune can be returned between successive calls to NextRune.
+func (rd *Reader) Unread(r rune) {
+	rd.loc = rd.prev
+	rd.buf = append([]rune{r}, rd.buf...)
+}
+
+// Token reads runes until a terminal rune (See IsTerminal) and returns them
+// as a string. init is included in the token unless it is -1.
+func (rd *Reader) Token(init rune) (string, error) {
+	var sb strings.Builder
+	if init != -1 {
+		sb.WriteRune(init)
+	}
+
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", err
+		}
+
+		if rd.IsTerminal(r) {
+			rd.Unread(r)
+			break
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String(), nil
+}
+
+// Container reads nodes until the 'end' rune and returns a node of given kind
+// with all the nodes read as children. This can be used by macros to read
+// custom delimited forms.
+func (rd *Reader) Container(kind Kind, open string, end rune) (*Node, error) {
+	node := &Node{Kind: kind, Text: open, Close: string(end)}
+
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: while reading %s",
+					sabre.ErrEOF, strings.ToLower(kind.String()))
+			}
+			return nil, err
+		}
+
+		if r == end {
+			return node, nil
+		}
+		rd.Unread(r)
+
+		child, err := rd.readOne()
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+}
+
+// Prefixed reads any trivia followed by one form and returns a node of given
+// kind with prefix as text and all the nodes read as children. This can be
+// used by macros to read prefix sugar like quote.
+func (rd *Reader) Prefixed(kind Kind, prefix string) (*Node, error) {
+	node := &Node{Kind: kind, Text: prefix}
+
+	for {
+		child, err := rd.readOne()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: while reading %s",
+					sabre.ErrEOF, strings.ToLower(kind.String()))
+			}
+			return nil, err
+		}
+
+		node.Children = append(node.Children, child)
+		if !child.IsTrivia() {
+			return node, nil
+		}
+	}
+}
+
+func (rd *Reader) readOne() (*Node, error) {
+	start := rd.loc
+
+	r, err := rd.NextRune()
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := rd.readNode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	node.Span = rd.span(start)
+	return node, nil
+}
+
+func (rd *Reader) readNode(r rune) (*Node, error) {
+	if isSpace(r) {
+		return readWhitespace(rd, r)
+	}
+
+	if unicode.IsNumber(r) {
+		return readToken(rd, Number, r)
+	} else if r == '+' || r == '-' {
+		r2, err := rd.NextRune()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if err != io.EOF {
+			rd.Unread(r2)
+			if unicode.IsNumber(r2) {
+				return readToken(rd, Number, r)
+			}
+		}
+	}
+
+	if macro, found := rd.macros[r]; found {
+		return macro(rd, r)
+	}
+
+	if r == dispatchTrigger {
+		return rd.execDispatch()
+	}
+
+	return readToken(rd, Symbol, r)
+}
+
+func (rd *Reader) execDispatch() (*Node, error) {
+	r, err := rd.NextRune()
+	if err != nil {
+		if err == io.EOF {
+			return &Node{Kind: Symbol, Text: string(dispatchTrigger)}, nil
+		}
+		return nil, err
+	}
+
+	if macro, found := rd.dispatch[r]; found {
+		node, err := macro(rd, r)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Text = string(dispatchTrigger) + node.Text
+		return node, nil
+	}
+
+	rd.Unread(r)
+	if rd.IsTerminal(r) {
+		return readToken(rd, Symbol, dispatchTrigger)
+	}
+
+	tag, err := rd.Token(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return rd.Prefixed(Tagged, string(dispatchTrigger)+tag)
+}
+
+func (rd *Reader) span(start sabre.Location) sabre.Span {
+	return sabre.Span{
+		File:  rd.File,
+		Start: start,
+		End:   rd.loc,
+	}
+}
+
+func readWhitespace(rd *Reader, init rune) (*Node, error) {
+	var sb strings.Builder
+	sb.WriteRune(init)
+
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if !isSpace(r) {
+			rd.Unread(r)
+			break
+		}
+		sb.WriteRune(r)
+	}
+
+	return &Node{Kind: Whitespace, Text: sb.String()}, nil
+}
+
+func readToken(rd *Reader, kind Kind, init rune) (*Node, error) {
+	token, err := rd.Token(init)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{Kind: kind, Text: token}, nil
+}
+
+// readSymbolicValue reads the ##Inf, ##-Inf and ##NaN numbers.
+func readSymbolicValue(rd *Reader, init rune) (*Node, error) {
+	return readToken(rd, Number, init)
+}
+
+func readKeyword(rd *Reader, init rune) (*Node, error) {
+	token, err := rd.Token(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{Kind: Keyword, Text: string(init) + token}, nil
+}
+
+func readCharacter(rd *Reader, init rune) (*Node, error) {
+	r, err := rd.NextRune()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: while reading character", sabre.ErrEOF)
+		}
+		return nil, err
+	}
+
+	token, err := rd.Token(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{Kind: Character, Text: string(init) + token}, nil
+}
+
+func readComment(rd *Reader, init rune) (*Node, error) {
+	var sb strings.Builder
+	sb.WriteRune(init)
+
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		if r == '\n' {
+			rd.Unread(r)
+			break
+		}
+		sb.WriteRune(r)
+	}
+
+	return &Node{Kind: Comment, Text: sb.String()}, nil
+}
+
+func stringReader(kind Kind) Macro {
+	return func(rd *Reader, init rune) (*Node, error) {
+		var sb strings.Builder
+		sb.WriteRune(init)
+
+		escaped := false
+		for {
+			r, err := rd.NextRune()
+			if err != nil {
+				if err == io.EOF {
+					return nil, fmt.Errorf("%w: while reading %s",
+						sabre.ErrEOF, strings.ToLower(kind.String()))
+				}
+				return nil, err
+			}
+
+			sb.WriteRune(r)
+			if r == '"' && !escaped {
+				break
+			}
+			escaped = r == '\\' && !escaped
+		}
+
+		return &Node{Kind: kind, Text: sb.String()}, nil
+	}
+}
+
+func containerReader(kind Kind, end rune) Macro {
+	return func(rd *Reader, init rune) (*Node, error) {
+		return rd.Container(kind, string(init), end)
+	}
+}
+
+func prefixReader(kind Kind) Macro {
+	return func(rd *Reader, init rune) (*Node, error) {
+		return rd.Prefixed(kind, string(init))
+	}
+}
+
+func unmatchedDelimiter(_ *Reader, init rune) (*Node, error) {
+	return nil, fmt.Errorf("unmatched delimiter '%c'", init)
+}
+
+func defaultReadTable() map[rune]Macro {
+	return map[rune]Macro{
+		'"':  stringReader(String),
+		';':  readComment,
+		':':  readKeyword,
+		'\\': readCharacter,
+		'\'': prefixReader(Quote),
+		'~':  prefixReader(Unquote),
+		'`':  prefixReader(SyntaxQuote),
+		'(':  containerReader(List, ')'),
+		')':  unmatchedDelimiter,
+		'[':  containerReader(Vector, ']'),
+		']':  unmatchedDelimiter,
+		'{':  containerReader(HashMap, '}'),
+		'}':  unmatchedDelimiter,
+	}
+}
+
+func defaultDispatchTable() map[rune]Macro {
+	return map[rune]Macro{
+		'{':  containerReader(Set, '}'),
+		'}':  unmatchedDelimiter,
+		'_':  prefixReader(Discard),
+		'(':  containerReader(AnonFn, ')'),
+		'"':  stringReader(Regex),
+		'\'': prefixReader(VarQuote),
+		'#':  readSymbolicValue,
+	}
+}
+
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r) || r == ','
+}
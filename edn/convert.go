@@ -0,0 +1,496 @@
+package edn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(UUID{})
+)
+
+// Unmarshal reads the first EDN element from data and stores the result in
+// the value pointed to by v. Struct fields are matched against hash-map keys
+// as described in sabre.StructFields() (case-insensitive).
+func Unmarshal(data []byte, v interface{}) error {
+	form, err := NewReader(bytes.NewReader(data)).One()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("%w: no edn element found", sabre.ErrEOF)
+		}
+		return err
+	}
+
+	return Decode(form, v)
+}
+
+// Marshal returns the EDN representation of the Go value v. Maps with string
+// keys and structs are written as hash-maps with keyword keys.
+func Marshal(v interface{}) ([]byte, error) {
+	val, err := FromGo(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(sabre.PrStr(val)), nil
+}
+
+// Decode stores the EDN value in the Go value pointed to by target.
+func Decode(v sabre.Value, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("decode target must be a non-nil pointer")
+	}
+
+	return decode(v, rv.Elem())
+}
+
+// ToGo converts the EDN value to plain Go value. Collections are converted
+// to []interface{}, hash-maps with string, keyword or symbol keys to
+// map[string]interface{} and other hash-maps to map[interface{}]interface{}.
+func ToGo(v sabre.Value) (interface{}, error) {
+	switch val := v.(type) {
+	case nil, sabre.Nil:
+		return nil, nil
+
+	case sabre.Bool:
+		return bool(val), nil
+
+	case sabre.Int64:
+		return int64(val), nil
+
+	case sabre.Float64:
+		return float64(val), nil
+
+	case sabre.String:
+		return string(val), nil
+
+	case sabre.Character:
+		return rune(val), nil
+
+	case sabre.Keyword:
+		return string(val), nil
+
+	case sabre.Symbol:
+		return val.Value, nil
+
+	case sabre.Any:
+		if !val.V.IsValid() || !val.V.CanInterface() {
+			return nil, fmt.Errorf("value '%s' has no go representation", val)
+		}
+		return val.V.Interface(), nil
+
+	case UUID:
+		return val, nil
+
+	case *sabre.HashMap:
+		return hashMapToGo(val)
+
+	case sabre.Seq:
+		var res []interface{}
+		for seq := sabre.Seq(val); seq != nil && seq.First() != nil; seq = seq.Next() {
+			item, err := ToGo(seq.First())
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, item)
+		}
+		return res, nil
+
+	default:
+		return nil, fmt.Errorf("value of type '%s' has no go representation",
+			reflect.TypeOf(v))
+	}
+}
+
+// FromGo converts the Go value to an EDN value.
+func FromGo(v interface{}) (sabre.Value, error) {
+	if v == nil {
+		return sabre.Nil{}, nil
+	}
+
+	if val, isValue := v.(sabre.Value); isValue {
+		return val, nil
+	}
+
+	return fromGo(reflect.ValueOf(v))
+}
+
+func fromGo(rv reflect.Value) (sabre.Value, error) {
+	if rv.Type() == timeType {
+		return sabre.ValueOf(rv.Interface()), nil
+	}
+
+	if rv.Kind() != reflect.Ptr && rv.Kind() != reflect.Interface {
+		if val, isValue := rv.Interface().(sabre.Value); isValue {
+			return val, nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return sabre.Nil{}, nil
+		}
+		return FromGo(rv.Elem().Interface())
+
+	case reflect.Bool:
+		return sabre.Bool(rv.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sabre.Int64(rv.Int()), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sabre.Int64(rv.Uint()), nil
+
+	case reflect.Float32, reflect.Float64:
+		return sabre.Float64(rv.Float()), nil
+
+	case reflect.String:
+		return sabre.String(rv.String()), nil
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return sabre.Nil{}, nil
+		}
+
+		vals := make(sabre.Values, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := fromGo(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = item
+		}
+		return sabre.Vector{Values: vals}, nil
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return sabre.Nil{}, nil
+		}
+
+		hm := &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}}
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := mapKeyFromGo(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+
+			val, err := fromGo(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+
+			if err := hm.Set(key, val); err != nil {
+				return nil, err
+			}
+		}
+		return hm, nil
+
+	case reflect.Struct:
+		return structFromGo(rv)
+
+	default:
+		return nil, fmt.Errorf("value of type '%s' cannot be converted to edn", rv.Type())
+	}
+}
+
+func mapKeyFromGo(rv reflect.Value) (sabre.Value, error) {
+	if rv.Kind() == reflect.String {
+		return sabre.Keyword(rv.String()), nil
+	}
+	return fromGo(rv)
+}
+
+func structFromGo(rv reflect.Value) (sabre.Value, error) {
+	hm := &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}}
+
+	for _, f := range sabre.StructFields(rv.Type()) {
+		fv, ok := f.Value(rv)
+		if !ok || (f.OmitEmpty && sabre.IsEmptyValue(fv)) {
+			continue
+		}
+
+		val, err := fromGo(fv)
+		if err != nil {
+			return nil, err
+		}
+		hm.Data[sabre.Keyword(f.Name)] = val
+	}
+
+	return hm, nil
+}
+
+func hashMapToGo(hm *sabre.HashMap) (interface{}, error) {
+	stringKeys := true
+	for _, k := range hm.Keys() {
+		switch k.(type) {
+		case sabre.String, sabre.Keyword, sabre.Symbol:
+		default:
+			stringKeys = false
+		}
+	}
+
+	if stringKeys {
+		res := map[string]interface{}{}
+		for k, v := range hm.Data {
+			key, _ := ToGo(k)
+			val, err := ToGo(v)
+			if err != nil {
+				return nil, err
+			}
+			res[key.(string)] = val
+		}
+		return res, nil
+	}
+
+	res := map[interface{}]interface{}{}
+	for k, v := range hm.Data {
+		key, err := ToGo(k)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := ToGo(v)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = val
+	}
+	return res, nil
+}
+
+func decode(v sabre.Value, rv reflect.Value) error {
+	if v == nil {
+		v = sabre.Nil{}
+	}
+
+	if _, isNil := v.(sabre.Nil); isNil {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+	}
+
+	switch rv.Type() {
+	case timeType:
+		inst, ok := v.(sabre.Any)
+		if !ok || !inst.V.IsValid() || inst.V.Type() != timeType {
+			return mismatchErr(v, rv.Type())
+		}
+		rv.Set(inst.V)
+		return nil
+
+	case uuidType:
+		id, ok := v.(UUID)
+		if !ok {
+			return mismatchErr(v, rv.Type())
+		}
+		rv.Set(reflect.ValueOf(id))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		goVal, err := ToGo(v)
+		if err != nil {
+			return err
+		}
+		if goVal == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+
+		gv := reflect.ValueOf(goVal)
+		if !gv.Type().AssignableTo(rv.Type()) {
+			return mismatchErr(v, rv.Type())
+		}
+		rv.Set(gv)
+		return nil
+
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(v, rv.Elem())
+
+	case reflect.Bool:
+		b, ok := v.(sabre.Bool)
+		if !ok {
+			return mismatchErr(v, rv.Type())
+		}
+		rv.SetBool(bool(b))
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch num := v.(type) {
+		case sabre.Int64:
+			n = int64(num)
+		case sabre.Character:
+			n = int64(num)
+		default:
+			return mismatchErr(v, rv.Type())
+		}
+
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows '%s'", n, rv.Type())
+		}
+		rv.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(sabre.Int64)
+		if !ok {
+			return mismatchErr(v, rv.Type())
+		}
+
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %d overflows '%s'", n, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch num := v.(type) {
+		case sabre.Float64:
+			rv.SetFloat(float64(num))
+		case sabre.Int64:
+			rv.SetFloat(float64(num))
+		default:
+			return mismatchErr(v, rv.Type())
+		}
+		return nil
+
+	case reflect.String:
+		switch s := v.(type) {
+		case sabre.String:
+			rv.SetString(string(s))
+		case sabre.Keyword:
+			rv.SetString(string(s))
+		case sabre.Symbol:
+			rv.SetString(s.Value)
+		default:
+			return mismatchErr(v, rv.Type())
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		return decodeSeq(v, rv)
+
+	case reflect.Map:
+		return decodeMap(v, rv)
+
+	case reflect.Struct:
+		return decodeStruct(v, rv)
+	}
+
+	return fmt.Errorf("cannot decode into value of type '%s'", rv.Type())
+}
+
+func decodeSeq(v sabre.Value, rv reflect.Value) error {
+	seq, ok := v.(sabre.Seq)
+	if !ok {
+		return mismatchErr(v, rv.Type())
+	}
+
+	var items []sabre.Value
+	for ; seq != nil && seq.First() != nil; seq = seq.Next() {
+		items = append(items, seq.First())
+	}
+
+	if rv.Kind() == reflect.Array {
+		if len(items) != rv.Len() {
+			return fmt.Errorf("expecting %d items for '%s', got %d",
+				rv.Len(), rv.Type(), len(items))
+		}
+	} else {
+		rv.Set(reflect.MakeSlice(rv.Type(), len(items), len(items)))
+	}
+
+	for i, item := range items {
+		if err := decode(item, rv.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeMap(v sabre.Value, rv reflect.Value) error {
+	hm, ok := v.(*sabre.HashMap)
+	if !ok {
+		return mismatchErr(v, rv.Type())
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(hm.Data)))
+	}
+
+	for k, val := range hm.Data {
+		key := reflect.New(rv.Type().Key()).Elem()
+		if err := decode(k, key); err != nil {
+			return err
+		}
+
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := decode(val, elem); err != nil {
+			return err
+		}
+
+		rv.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+func decodeStruct(v sabre.Value, rv reflect.Value) error {
+	hm, ok := v.(*sabre.HashMap)
+	if !ok {
+		return mismatchErr(v, rv.Type())
+	}
+
+	fields := sabre.StructFields(rv.Type())
+	for k, val := range hm.Data {
+		name, isName := keyName(k)
+		if !isName {
+			continue
+		}
+
+		f, found := sabre.FindField(fields, name)
+		if !found {
+			continue
+		}
+
+		if err := decode(val, f.Settable(rv)); err != nil {
+			return fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func keyName(k sabre.Value) (string, bool) {
+	switch key := k.(type) {
+	case sabre.Keyword:
+		return string(key), true
+	case sabre.String:
+		return string(key), true
+	case sabre.Symbol:
+		return key.Value, true
+	}
+	return "", false
+}
+
+func mismatchErr(v sabre.Value, rt reflect.Type) error {
+	return fmt.Errorf("cannot decode '%s' into value of type '%s'", sabre.PrStr(v), rt)
+}
@@ -0,0 +1,69 @@
+// Package edn provides reading and writing of data in Extensible Data
+// Notation (https://github.com/edn-format/edn) using the sabre Reader with
+// a restricted read table. Forms read by this package are never evaluated.
+package edn
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spy16/sabre"
+)
+
+// TagHandler is invoked with the form following a tag (e.g., the string in
+// '#inst "2020-01-01"') and should return the value the tagged element
+// represents.
+type TagHandler = sabre.TagFunc
+
+// NewReader returns an EDN reader that reads from r. Quote, syntax-quote,
+// unquote, var-quote, regex and anonymous function reader macros are replaced
+// with macros that fail since EDN does not support them. '#inst' and '#uuid' tags are supported by default and
+// more can be added using SetTag(). Unlike sabre.Reader, unknown tags are
+// an error unless DefaultTag is set.
+func NewReader(r io.Reader) *Reader {
+	rd := &Reader{Reader: sabre.NewReader(r)}
+
+	for _, r := range "'~`" {
+		rd.SetMacro(r, unsupported(""), false)
+	}
+
+	for _, r := range "'(\"" {
+		rd.SetMacro(r, unsupported("#"), true)
+	}
+	rd.DefaultTag = unknownTag
+
+	return rd
+}
+
+// Reader reads EDN elements from a stream.
+type Reader struct {
+	*sabre.Reader
+}
+
+// All reads all the elements until EOF.
+func (rd *Reader) All() ([]sabre.Value, error) {
+	var vals []sabre.Value
+	for {
+		v, err := rd.One()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// unsupported returns a reader macro that fails for the form starting with
+// prefix followed by the init rune.
+func unsupported(prefix string) sabre.ReaderMacro {
+	return func(_ *sabre.Reader, init rune) (sabre.Value, error) {
+		return nil, fmt.Errorf("'%s%c' is not supported in EDN", prefix, init)
+	}
+}
+
+func unknownTag(tag string, _ sabre.Value) (sabre.Value, error) {
+	return nil, fmt.Errorf("no handler for tag '#%s'", tag)
+}
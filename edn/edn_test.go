@@ -0,0 +1,293 @@
+package edn_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/edn"
+)
+
+func TestReader_One(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr bool
+	}{
+		{
+			name: "Keyword",
+			src:  `:name`,
+			want: sabre.Keyword("name"),
+		},
+		{
+			name:    "Quote",
+			src:     `'hello`,
+			wantErr: true,
+		},
+		{
+			name:    "SyntaxQuote",
+			src:     "`hello",
+			wantErr: true,
+		},
+		{
+			name:    "Unquote",
+			src:     `~hello`,
+			wantErr: true,
+		},
+		{
+			name:    "VarQuote",
+			src:     `#'hello`,
+			wantErr: true,
+		},
+		{
+			name:    "AnonFn",
+			src:     `#(inc %)`,
+			wantErr: true,
+		},
+		{
+			name:    "Regex",
+			src:     `#"a+"`,
+			wantErr: true,
+		},
+		{
+			name:    "BareDispatch",
+			src:     `# 1`,
+			wantErr: true,
+		},
+		{
+			name:    "DispatchAtEOF",
+			src:     `#`,
+			wantErr: true,
+		},
+		{
+			name: "Set",
+			src:  `#{1 #_2 3}`,
+			want: sabre.Set{Values: sabre.Values{sabre.Int64(1), sabre.Int64(3)}},
+		},
+		{
+			name: "Discard",
+			src:  `#_ (foo bar) [1 #_ 2]`,
+			want: sabre.Vector{Values: sabre.Values{sabre.Int64(1)}},
+		},
+		{
+			name: "Inst",
+			src:  `#inst "2020-01-01T10:00:00Z"`,
+			want: sabre.ValueOf(time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "UUID",
+			src:  `#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`,
+			want: mustUUID("f81d4fae-7dec-11d0-a765-00a0c91e6bf6"),
+		},
+		{
+			name:    "InvalidUUID",
+			src:     `#uuid "f81d4fae"`,
+			wantErr: true,
+		},
+		{
+			name:    "UnknownTag",
+			src:     `#foo/bar 10`,
+			wantErr: true,
+		},
+		{
+			name:    "DuplicateInSet",
+			src:     `#{1 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "TagWithoutElement",
+			src:     `#inst`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := edn.NewReader(strings.NewReader(tt.src)).One()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("One() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !sabre.Compare(got, tt.want) {
+				t.Errorf("One() got = %#v, want = %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReader_SetTag(t *testing.T) {
+	t.Parallel()
+
+	rd := edn.NewReader(strings.NewReader(`#my/double 21 #unknown 1`))
+	rd.SetTag("my/double", func(form sabre.Value) (sabre.Value, error) {
+		return form.(sabre.Int64) * 2, nil
+	})
+	rd.DefaultTag = func(tag string, form sabre.Value) (sabre.Value, error) {
+		return sabre.String(tag), nil
+	}
+
+	got, err := rd.All()
+	if err != nil {
+		t.Fatalf("All() unexpected error: %v", err)
+	}
+
+	want := []sabre.Value{sabre.Int64(42), sabre.String("unknown")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All() got = %v, want = %v", got, want)
+	}
+}
+
+type config struct {
+	Name    string            `edn:"name"`
+	Port    uint16            `edn:"port"`
+	Ratio   float64           `edn:"ratio,omitempty"`
+	Tags    []string          `edn:"tags"`
+	Limits  map[string]int    `edn:"limits"`
+	Created time.Time         `edn:"created"`
+	ID      edn.UUID          `edn:"id"`
+	Extra   map[string]string `edn:"-"`
+	Nested  *config           `edn:"nested,omitempty"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	src := `{:name "api" ; service name
+	         :port 8080
+	         :tags [:a "b" c]
+	         :limits {:rps 100}
+	         :created #inst "2020-01-01"
+	         :id #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"
+	         :nested {:name "child" :ratio 1}}`
+
+	var got config
+	if err := edn.Unmarshal([]byte(src), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	want := config{
+		Name:    "api",
+		Port:    8080,
+		Tags:    []string{"a", "b", "c"},
+		Limits:  map[string]int{"rps": 100},
+		Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		ID:      mustUUID("f81d4fae-7dec-11d0-a765-00a0c91e6bf6"),
+		Nested:  &config{Name: "child", Ratio: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got = %#v, want = %#v", got, want)
+	}
+
+	var generic map[string]interface{}
+	if err := edn.Unmarshal([]byte(`{:a [1 2.5 "x"] "b" nil}`), &generic); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	wantGeneric := map[string]interface{}{
+		"a": []interface{}{int64(1), 2.5, "x"},
+		"b": nil,
+	}
+	if !reflect.DeepEqual(generic, wantGeneric) {
+		t.Errorf("Unmarshal() got = %#v, want = %#v", generic, wantGeneric)
+	}
+
+	var port uint8
+	if err := edn.Unmarshal([]byte(`1000`), &port); err == nil {
+		t.Errorf("Unmarshal() expected overflow error, got nil")
+	}
+
+	if err := edn.Unmarshal([]byte(``), &port); !errors.Is(err, sabre.ErrEOF) {
+		t.Errorf("Unmarshal() expected ErrEOF, got %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	cfg := config{
+		Name:    "api",
+		Port:    8080,
+		Tags:    []string{"a"},
+		Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	got, err := edn.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	want := `{:created #inst "2020-01-01T00:00:00Z" ` +
+		`:id #uuid "00000000-0000-0000-0000-000000000000" ` +
+		`:limits nil :name "api" :port 8080 :tags ["a"]}`
+	if string(got) != want {
+		t.Errorf("Marshal() got = %s\nwant = %s", got, want)
+	}
+
+	var back config
+	if err := edn.Unmarshal(got, &back); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(back, cfg) {
+		t.Errorf("round-trip got = %#v, want = %#v", back, cfg)
+	}
+
+	if _, err := edn.Marshal(func() {}); err == nil {
+		t.Errorf("Marshal() expected error for func value, got nil")
+	}
+}
+
+type Meta struct {
+	Team string `json:"team"`
+}
+
+type service struct {
+	Meta
+	Name  string `sabre:"name" edn:"svc-name" json:"service"`
+	Owner string `edn:"owner,omitempty" json:"lead"`
+}
+
+func TestMarshal_StructFields(t *testing.T) {
+	t.Parallel()
+
+	svc := service{Meta: Meta{Team: "core"}, Name: "api"}
+	got, err := edn.Marshal(svc)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	hm, err := sabre.StructToMap(svc)
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+
+	want := `{:name "api" :team "core"}`
+	if string(got) != want || sabre.PrStr(hm) != want {
+		t.Errorf("expected Marshal() and StructToMap() to produce %s, got %s and %s",
+			want, got, sabre.PrStr(hm))
+	}
+
+	var back service
+	if err := edn.Unmarshal([]byte(`{:NAME "web" :team "infra" :owner "bob"}`), &back); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	wantBack := service{Meta: Meta{Team: "infra"}, Name: "web", Owner: "bob"}
+	if back != wantBack {
+		t.Errorf("Unmarshal() got = %#v, want = %#v", back, wantBack)
+	}
+}
+
+func mustUUID(s string) edn.UUID {
+	id, err := edn.ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
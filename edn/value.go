@@ -0,0 +1,9 @@
+package edn
+
+import "github.com/spy16/sabre"
+
+// UUID represents an '#uuid' tagged element.
+type UUID = sabre.UUID
+
+// ParseUUID parses the canonical 8-4-4-4-12 hex representation of a UUID.
+func ParseUUID(s string) (UUID, error) { return sabre.ParseUUID(s) }
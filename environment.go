@@ -0,0 +1,204 @@
+package sabre
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Sealer can be implemented by Scope implementations that can be made
+// immutable. See MapScope.Seal().
+type Sealer interface {
+	Seal()
+}
+
+// EnvOption can be provided to NewEnvironment() to configure the environment.
+type EnvOption func(env *Environment)
+
+// Sealed seals the base scope and its parents that implement Sealer when the
+// environment is created so that any attempt to modify them (e.g., 'def'
+// evaluated directly against the base scope) fails with ErrSealed.
+func Sealed() EnvOption {
+	return func(env *Environment) {
+		env.sealed = true
+	}
+}
+
+// NewEnvironment returns an environment with the given scope as the base.
+// The base scope is shared by all the overlays created using Fork() and
+// must not be modified by evaluations. If base is nil, sabre.New() is used.
+func NewEnvironment(base Scope, opts ...EnvOption) *Environment {
+	if base == nil {
+		base = New()
+	}
+
+	env := &Environment{base: base}
+	for _, opt := range opts {
+		opt(env)
+	}
+
+	if env.sealed {
+		for s := base; s != nil; s = s.Parent() {
+			if sealer, ok := s.(Sealer); ok {
+				sealer.Seal()
+			}
+		}
+	}
+
+	return env
+}
+
+// Environment provides isolated scopes for evaluations sharing a large base
+// scope (e.g., one evaluation per request of a tenant).
+type Environment struct {
+	base   Scope
+	sealed bool
+}
+
+// Base returns the base scope of the environment.
+func (env *Environment) Base() Scope { return env.base }
+
+// Fork returns a new copy-on-write overlay of the base scope. Creating an
+// overlay does not copy the base scope.
+func (env *Environment) Fork() *Overlay {
+	return &Overlay{
+		base:     env.base,
+		mu:       new(sync.RWMutex),
+		bindings: map[string]Value{},
+		hidden:   map[string]bool{},
+	}
+}
+
+// Overlay is a copy-on-write view of the base scope of an Environment. All
+// the bindings of the base scope are visible in the overlay but changes to
+// the overlay (including 'def') never modify the base scope. Overlay has no
+// parent and acts as the root scope for the evaluation.
+type Overlay struct {
+	base     Scope
+	mu       *sync.RWMutex
+	bindings map[string]Value
+	hidden   map[string]bool
+}
+
+// Parent always returns nil since the overlay is a root scope.
+func (ov *Overlay) Parent() Scope { return nil }
+
+// Bind binds the symbol in the overlay. Bindings with same symbol in the base
+// scope are shadowed.
+func (ov *Overlay) Bind(symbol string, v Value) error {
+	ov.mu.Lock()
+	defer ov.mu.Unlock()
+
+	delete(ov.hidden, symbol)
+	ov.bindings[symbol] = v
+	return nil
+}
+
+// BindGo is similar to Bind but handles conversion of Go value 'v' to
+// sabre Value type. See `ValueOf()`
+func (ov *Overlay) BindGo(symbol string, v interface{}) error {
+	return ov.Bind(symbol, ValueOf(v))
+}
+
+// Resolve finds the value bound to the symbol in the overlay and falls back
+// to the base scope.
+func (ov *Overlay) Resolve(symbol string) (Value, error) {
+	ov.mu.RLock()
+	v, found := ov.bindings[symbol]
+	hidden := ov.hidden[symbol]
+	ov.mu.RUnlock()
+
+	if found {
+		return v, nil
+	}
+
+	if hidden {
+		return nil, fmt.Errorf("%w: %v", ErrResolving, symbol)
+	}
+
+	return ov.base.Resolve(symbol)
+}
+
+// Unbind removes the binding from the overlay. Bindings of the base scope are
+// hidden from the overlay instead.
+func (ov *Overlay) Unbind(symbol string) error {
+	ov.mu.Lock()
+	defer ov.mu.Unlock()
+
+	if _, found := ov.bindings[symbol]; found {
+		delete(ov.bindings, symbol)
+	} else if ov.hidden[symbol] {
+		return fmt.Errorf("%w: %v", ErrResolving, symbol)
+	} else if _, err := ov.base.Resolve(symbol); err != nil {
+		return err
+	}
+
+	ov.hidden[symbol] = true
+	return nil
+}
+
+// Bindings returns all the bindings visible in the overlay including the
+// bindings of the base scope.
+func (ov *Overlay) Bindings() map[string]Value {
+	bindings := Bindings(ov.base)
+
+	ov.mu.RLock()
+	defer ov.mu.RUnlock()
+
+	for symbol := range ov.hidden {
+		delete(bindings, symbol)
+	}
+
+	for symbol, v := range ov.bindings {
+		bindings[symbol] = v
+	}
+	return bindings
+}
+
+// Snapshot captures the changes made in the overlay. Since the base scope is
+// not captured, this is cheap even when the base scope is large.
+func (ov *Overlay) Snapshot() Snapshot {
+	ov.mu.RLock()
+	defer ov.mu.RUnlock()
+
+	return overlaySnapshot{
+		bindings: copyBindings(ov.bindings),
+		hidden:   copyHidden(ov.hidden),
+	}
+}
+
+// Restore replaces the changes in the overlay with the ones captured using
+// Snapshot() of an Overlay.
+func (ov *Overlay) Restore(snapshot Snapshot) error {
+	snap, ok := snapshot.(overlaySnapshot)
+	if !ok {
+		return fmt.Errorf("cannot restore snapshot of type %T", snapshot)
+	}
+
+	ov.mu.Lock()
+	defer ov.mu.Unlock()
+
+	ov.bindings = copyBindings(snap.bindings)
+	ov.hidden = copyHidden(snap.hidden)
+	return nil
+}
+
+type overlaySnapshot struct {
+	bindings map[string]Value
+	hidden   map[string]bool
+}
+
+func copyBindings(bindings map[string]Value) map[string]Value {
+	res := make(map[string]Value, len(bindings))
+	for symbol, v := range bindings {
+		res[symbol] = v
+	}
+	return res
+}
+
+func copyHidden(hidden map[string]bool) map[string]bool {
+	res := make(map[string]bool, len(hidden))
+	for symbol := range hidden {
+		res[symbol] = true
+	}
+	return res
+}
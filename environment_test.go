@@ -0,0 +1,118 @@
+package sabre_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+var (
+	_ sabre.Scope         = (*sabre.Overlay)(nil)
+	_ sabre.BindingLister = (*sabre.Overlay)(nil)
+	_ sabre.Unbinder      = (*sabre.Overlay)(nil)
+	_ sabre.Snapshotter   = (*sabre.Overlay)(nil)
+)
+
+func TestEnvironment_Fork(t *testing.T) {
+	t.Parallel()
+
+	base := sabre.New()
+	_ = base.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+	env := sabre.NewEnvironment(base)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			scope := env.Fork()
+			_ = scope.BindGo("tenant", i)
+
+			got, err := sabre.ReadEvalStr(scope, "(def x (inc tenant)) (def inc 0) x")
+			if err != nil {
+				t.Errorf("ReadEvalStr() unexpected error: %v", err)
+				return
+			}
+
+			if got != sabre.Int64(i+1) {
+				t.Errorf("ReadEvalStr() want=%d, got=%v", i+1, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := base.Resolve("x"); err == nil {
+		t.Errorf("expected def to not modify the base scope")
+	}
+
+	if got, _ := sabre.ReadEvalStr(base, "(inc 1)"); got != sabre.Int64(2) {
+		t.Errorf("expected base binding to be intact, got %v", got)
+	}
+}
+
+func TestOverlay_Unbind(t *testing.T) {
+	t.Parallel()
+
+	base := sabre.NewScope(nil)
+	_ = base.Bind("pi", sabre.Float64(3.1412))
+
+	scope := sabre.NewEnvironment(base).Fork()
+	snapshot := scope.Snapshot()
+
+	if err := scope.Unbind("pi"); err != nil {
+		t.Fatalf("Unbind() unexpected error: %v", err)
+	}
+
+	if _, err := scope.Resolve("pi"); !errors.Is(err, sabre.ErrResolving) {
+		t.Errorf("Resolve() expected ErrResolving after Unbind(), got %v", err)
+	}
+
+	if _, found := scope.Bindings()["pi"]; found {
+		t.Errorf("Bindings() expected 'pi' to be hidden")
+	}
+
+	if _, err := base.Resolve("pi"); err != nil {
+		t.Errorf("expected Unbind() to not modify the base scope: %v", err)
+	}
+
+	if err := scope.Unbind("pi"); !errors.Is(err, sabre.ErrResolving) {
+		t.Errorf("Unbind() expected ErrResolving, got %v", err)
+	}
+
+	if err := scope.Restore(snapshot); err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+
+	if got, err := scope.Resolve("pi"); err != nil || got != sabre.Float64(3.1412) {
+		t.Errorf("Resolve() expected 'pi' after Restore(), got = %v, err = %v", got, err)
+	}
+}
+
+func TestEnvironment_Sealed(t *testing.T) {
+	t.Parallel()
+
+	root := sabre.New()
+	base := sabre.NewScope(root)
+	env := sabre.NewEnvironment(base, sabre.Sealed())
+
+	for _, scope := range []*sabre.MapScope{root, base} {
+		if err := scope.Bind("x", sabre.Int64(1)); !errors.Is(err, sabre.ErrSealed) {
+			t.Errorf("Bind() expected ErrSealed, got %v", err)
+		}
+	}
+
+	if _, err := sabre.ReadEvalStr(base, "(def x 1)"); !errors.Is(err, sabre.ErrSealed) {
+		t.Errorf("ReadEvalStr() expected ErrSealed, got %v", err)
+	}
+
+	if err := root.Unbind("def"); !errors.Is(err, sabre.ErrSealed) {
+		t.Errorf("Unbind() expected ErrSealed, got %v", err)
+	}
+
+	if _, err := sabre.ReadEvalStr(env.Fork(), "(def x 1)"); err != nil {
+		t.Errorf("ReadEvalStr() unexpected error: %v", err)
+	}
+}
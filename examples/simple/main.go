@@ -20,6 +20,8 @@ func main() {
 
 	repl.New(scope,
 		repl.WithPrompts("=>", ">"),
+		repl.WithLineEditor(),
+		repl.WithCommands(repl.DefaultCommands()...),
 	).Loop(context.Background())
 }
 
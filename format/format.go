@@ -0,0 +1,270 @@
+// Package format implements canonical formatting of sabre source. Line breaks
+// chosen by the author are retained (runs of blank lines are collapsed into
+// one) while indentation and spacing within lines are normalized. Comments
+// are preserved. Formatting is idempotent: formatting already formatted
+// source returns it unchanged.
+package format
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spy16/sabre/cst"
+	"github.com/spy16/sabre/pprint"
+)
+
+// DefaultIndent is the number of spaces used for indenting bodies of body
+// style forms when no indent is configured.
+const DefaultIndent = 2
+
+// Source formats src using the default formatter and returns the result.
+func Source(src []byte) ([]byte, error) {
+	return New().Source(src)
+}
+
+// New returns a new formatter configured with given options. Forms returned
+// by pprint.DefaultBodyForms() (e.g., 'fn*', 'let*', 'do') are indented in
+// body style by default, same as the pretty printer.
+func New(opts ...Option) *Formatter {
+	f := &Formatter{
+		indent:    DefaultIndent,
+		bodyForms: pprint.DefaultBodyForms(),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Formatter formats sabre source into canonical form. Contents of a list are
+// indented in one of the following ways:
+//
+//	(fn* [a b]        ; body style forms indent the body by 'indent'
+//	  (+ a b))
+//	(foo a            ; calls align arguments with the first argument
+//	     b)
+//	(foo              ; or by one space if first argument is on a new line
+//	 a b)
+//
+// Contents of vectors, hash-maps and sets are aligned with the first item.
+type Formatter struct {
+	indent    int
+	bodyForms map[string]int
+}
+
+// Source formats src and returns the result. Returns error if the source is
+// not syntactically valid.
+func (f *Formatter) Source(src []byte) ([]byte, error) {
+	root, err := cst.NewReader(bytes.NewReader(src)).All()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := f.Fprint(&buf, root); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Fprint writes the formatted source of the syntax tree node to w. If the
+// node is a root node, output ends with a newline.
+func (f *Formatter) Fprint(w io.Writer, node *cst.Node) error {
+	p := &printer{f: f}
+	if node.Kind == cst.Root {
+		p.root(node)
+	} else {
+		p.node(node)
+	}
+
+	_, err := io.WriteString(w, p.sb.String())
+	return err
+}
+
+type printer struct {
+	f   *Formatter
+	sb  strings.Builder
+	col int
+}
+
+func (p *printer) node(n *cst.Node) {
+	switch {
+	case n.IsContainer():
+		p.container(n)
+
+	case len(n.Children) > 0:
+		p.prefixed(n)
+
+	case n.Kind == cst.Comment:
+		p.write(strings.TrimRightFunc(n.Text, unicode.IsSpace))
+
+	default:
+		p.write(n.Text)
+	}
+}
+
+func (p *printer) root(n *cst.Node) {
+	var prev *cst.Node
+	for i, child := range n.Children {
+		if child.Kind == cst.Whitespace {
+			continue
+		}
+
+		if prev != nil {
+			p.separate(spaceBefore(n.Children, i), prev, 0)
+		}
+
+		p.node(child)
+		prev = child
+	}
+
+	if prev != nil {
+		p.write("\n")
+	}
+}
+
+func (p *printer) container(n *cst.Node) {
+	open := p.col
+	p.write(n.Text)
+
+	var head, prev *cst.Node
+	forms, argCol, sameLine := 0, -1, true
+	indent := func() int {
+		return p.indentFor(n, open, head, forms, argCol)
+	}
+
+	for i, child := range n.Children {
+		if child.Kind == cst.Whitespace {
+			continue
+		}
+
+		space := spaceBefore(n.Children, i)
+		if prev != nil {
+			newLine := p.separate(space, prev, indent())
+			sameLine = sameLine && !newLine
+		} else if child.Kind == cst.Comment && lineBreaks(space) > 0 {
+			p.newline(1, indent())
+			sameLine = false
+		}
+
+		if forms == 1 && sameLine && !child.IsTrivia() {
+			argCol = p.col
+		}
+
+		p.node(child)
+		prev = child
+
+		if !child.IsTrivia() {
+			if forms == 0 {
+				head = child
+			}
+			forms++
+		}
+	}
+
+	if prev != nil && prev.Kind == cst.Comment {
+		p.newline(1, indent())
+	}
+	p.write(n.Close)
+}
+
+func (p *printer) prefixed(n *cst.Node) {
+	start := p.col
+	p.write(n.Text)
+
+	var prev *cst.Node
+	for i, child := range n.Children {
+		if child.Kind == cst.Whitespace {
+			continue
+		}
+
+		if prev != nil {
+			p.separate(spaceBefore(n.Children, i), prev, start)
+		} else if n.Kind == cst.Tagged || child.Kind == cst.Comment {
+			p.write(" ")
+		}
+
+		p.node(child)
+		prev = child
+	}
+}
+
+// separate writes the separator between two adjacent nodes based on the
+// whitespace node between them (which can be nil). Returns true if the
+// separator contains a line break.
+func (p *printer) separate(space, prev *cst.Node, indent int) bool {
+	lines := lineBreaks(space)
+	if lines == 0 && prev.Kind == cst.Comment {
+		lines = 1
+	}
+
+	if lines > 0 {
+		if lines > 2 {
+			lines = 2
+		}
+		p.newline(lines, indent)
+		return true
+	}
+
+	if space != nil && strings.ContainsRune(space.Text, ',') {
+		p.write(", ")
+	} else {
+		p.write(" ")
+	}
+	return false
+}
+
+func (p *printer) indentFor(n *cst.Node, open int, head *cst.Node, formIdx, argCol int) int {
+	inner := open + utf8.RuneCountInString(n.Text)
+	if (n.Kind != cst.List && n.Kind != cst.AnonFn) || head == nil {
+		return inner
+	}
+
+	if head.Kind == cst.Symbol {
+		if headerArgs, isBody := p.f.bodyForms[head.Text]; isBody {
+			if formIdx <= headerArgs {
+				return open + 2*p.f.indent
+			}
+			return open + p.f.indent
+		}
+	}
+
+	if (head.Kind == cst.Symbol || head.Kind == cst.Keyword) && argCol >= 0 {
+		return argCol
+	}
+
+	return inner
+}
+
+func (p *printer) newline(n, indent int) {
+	p.write(strings.Repeat("\n", n) + strings.Repeat(" ", indent))
+}
+
+func (p *printer) write(s string) {
+	p.sb.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.col += utf8.RuneCountInString(s)
+	}
+}
+
+func spaceBefore(nodes []*cst.Node, i int) *cst.Node {
+	if i > 0 && nodes[i-1].Kind == cst.Whitespace {
+		return nodes[i-1]
+	}
+	return nil
+}
+
+func lineBreaks(space *cst.Node) int {
+	if space == nil {
+		return 0
+	}
+	return strings.Count(space.Text, "\n")
+}
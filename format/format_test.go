@@ -0,0 +1,136 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre/format"
+	"github.com/spy16/sabre/pprint"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name string
+		opts []format.Option
+		src  string
+		want string
+	}{
+		{
+			name: "Empty",
+			src:  "  \n\n",
+			want: "",
+		},
+		{
+			name: "SpacingWithinLine",
+			src:  "  ( def   x [ 1  2,3 ]  )   ",
+			want: "(def x [1 2, 3])\n",
+		},
+		{
+			name: "BlankLinesCollapsed",
+			src:  "(a)\n\n\n\n(b)\n(c)",
+			want: "(a)\n\n(b)\n(c)\n",
+		},
+		{
+			name: "BodyStyle",
+			src:  "(fn* [a b]\n(let* [c 1]\n      (+ a\n b c)))",
+			want: "(fn* [a b]\n  (let* [c 1]\n    (+ a\n       b c)))\n",
+		},
+		{
+			name: "HeaderArgOnNewLine",
+			src:  "(if\ncond\nthen\nelse)",
+			want: "(if\n    cond\n  then\n  else)\n",
+		},
+		{
+			name: "ArgsOnNextLine",
+			src:  "(foo\n    a\n        b)",
+			want: "(foo\n a\n b)\n",
+		},
+		{
+			name: "Collections",
+			src:  "[1\n2]\n{:a 1\n  :b 2}\n#{1\n 2}",
+			want: "[1\n 2]\n{:a 1\n :b 2}\n#{1\n  2}\n",
+		},
+		{
+			name: "Comments",
+			src:  ";; header   \n(do ; trailing\n  ;; own line\n    (foo) ; last\n)",
+			want: ";; header\n(do ; trailing\n  ;; own line\n  (foo) ; last\n  )\n",
+		},
+		{
+			name: "Sugar",
+			src:  "' x #_ (ignored) #inst\"2020-01-01\" #( + % 1 )",
+			want: "'x #_(ignored) #inst \"2020-01-01\" #(+ % 1)\n",
+		},
+		{
+			name: "AdjacentForms",
+			src:  "(a\"s\"(b))",
+			want: "(a \"s\" (b))\n",
+		},
+		{
+			name: "MultiLineString",
+			src:  "(foo \"a\nbc\" d\ne)",
+			want: "(foo \"a\nbc\" d\n     e)\n",
+		},
+		{
+			name: "CustomBodyForm",
+			opts: []format.Option{format.WithBodyForm("when", 1), format.WithIndent(4)},
+			src:  "(when x\ny)\n(do\nz)",
+			want: "(when x\n    y)\n(do\n    z)\n",
+		},
+		{
+			name: "RemoveBodyForm",
+			opts: []format.Option{format.WithBodyForm("do", -1)},
+			src:  "(do x\ny)",
+			want: "(do x\n    y)\n",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			f := format.New(tt.opts...)
+
+			got, err := f.Source([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Source() unexpected error: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("Source() got = %q, want = %q", got, tt.want)
+			}
+
+			again, err := f.Source(got)
+			if err != nil {
+				t.Fatalf("Source() unexpected error on formatted source: %v", err)
+			}
+
+			if string(again) != string(got) {
+				t.Errorf("Source() is not idempotent: got = %q, want = %q", again, got)
+			}
+		})
+	}
+}
+
+func TestSource_DefaultBodyForms(t *testing.T) {
+	t.Parallel()
+
+	for name, headerArgs := range pprint.DefaultBodyForms() {
+		header := "(" + name + strings.Repeat(" a", headerArgs)
+		got, err := format.Source([]byte(header + "\nx)"))
+		if err != nil {
+			t.Fatalf("Source() unexpected error: %v", err)
+		}
+
+		if want := header + "\n  x)\n"; string(got) != want {
+			t.Errorf("Source() expected '%s' in body style, got = %q, want = %q", name, got, want)
+		}
+	}
+}
+
+func TestSource_Error(t *testing.T) {
+	t.Parallel()
+
+	if _, err := format.Source([]byte("(foo [1 2)")); err == nil {
+		t.Errorf("Source() expected error for invalid source, got nil")
+	}
+}
@@ -0,0 +1,31 @@
+package format
+
+// Option implementations can be provided to New() to configure the formatter.
+type Option func(f *Formatter)
+
+// WithIndent sets the number of spaces used for indenting bodies of body
+// style forms. Header arguments that start on a new line are indented twice
+// as much. Non-positive values reset the indent to DefaultIndent.
+func WithIndent(indent int) Option {
+	if indent <= 0 {
+		indent = DefaultIndent
+	}
+
+	return func(f *Formatter) {
+		f.indent = indent
+	}
+}
+
+// WithBodyForm registers a list head symbol (e.g., a macro name) to be
+// indented in body style: the first 'headerArgs' arguments are treated as
+// the header of the form and remaining arguments as body. Negative headerArgs
+// removes the rule.
+func WithBodyForm(name string, headerArgs int) Option {
+	return func(f *Formatter) {
+		if headerArgs < 0 {
+			delete(f.bodyForms, name)
+			return
+		}
+		f.bodyForms[name] = headerArgs
+	}
+}
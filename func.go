@@ -192,6 +192,8 @@ type Fn struct {
 	Variadic bool
 	Body     Value
 	Func     func(scope Scope, args []Value) (Value, error)
+
+	layout *slotLayout
 }
 
 // Eval returns the function itself.
@@ -217,20 +219,23 @@ func (fn *Fn) Invoke(scope Scope, args ...Value) (Value, error) {
 		return fn.Func(scope, args)
 	}
 
-	fnScope := NewScope(scope)
-
+	values := make([]Value, len(fn.Args))
 	for idx := range fn.Args {
-		var argVal Value
 		if idx == len(fn.Args)-1 && fn.Variadic {
-			argVal = &List{
+			values[idx] = &List{
 				Values: args[idx:],
 			}
 		} else {
-			argVal = args[idx]
+			values[idx] = args[idx]
 		}
+	}
 
-		_ = fnScope.Bind(fn.Args[idx], argVal)
+	layout := fn.layout
+	if layout == nil {
+		// Fn was not created by fn* (e.g., created in Go).
+		layout = newSlotLayout(fn.Args)
 	}
+	fnScope := newLocalScope(scope, layout, values)
 
 	if fn.Body == nil {
 		return Nil{}, nil
@@ -296,6 +301,7 @@ func (fn *Fn) parseArgSpec(spec Value) error {
 		fn.Args = argNames
 	}
 
+	fn.layout = newSlotLayout(fn.Args)
 	return nil
 }
 
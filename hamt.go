@@ -0,0 +1,210 @@
+package sabre
+
+import "math/bits"
+
+// persistentMap is an immutable hash array mapped trie from symbols to
+// values. Every update returns a new map sharing unchanged nodes with the
+// original, which makes copies cheap and allows reads without locking.
+type persistentMap struct {
+	root *hamtNode
+	size int
+}
+
+type hamtNode struct {
+	bitmap  uint32
+	entries []hamtEntry
+}
+
+// hamtEntry is either a leaf holding a binding or a link to a sub-node.
+type hamtEntry struct {
+	hash  uint32
+	key   string
+	value Value
+	node  *hamtNode
+}
+
+const (
+	hamtBits  = 5
+	hamtMask  = 1<<hamtBits - 1
+	hamtDepth = 32 // nodes at this shift hold colliding entries as a list.
+)
+
+func (m *persistentMap) get(key string) (Value, bool) {
+	if m == nil || m.root == nil {
+		return nil, false
+	}
+
+	h := hashKey(key)
+	node := m.root
+	for shift := uint(0); ; shift += hamtBits {
+		if shift >= hamtDepth {
+			for _, e := range node.entries {
+				if e.key == key {
+					return e.value, true
+				}
+			}
+			return nil, false
+		}
+
+		bit := uint32(1) << ((h >> shift) & hamtMask)
+		if node.bitmap&bit == 0 {
+			return nil, false
+		}
+
+		e := node.entries[bits.OnesCount32(node.bitmap&(bit-1))]
+		if e.node == nil {
+			if e.key == key {
+				return e.value, true
+			}
+			return nil, false
+		}
+		node = e.node
+	}
+}
+
+func (m *persistentMap) set(key string, v Value) *persistentMap {
+	root := &hamtNode{}
+	size := 0
+	if m != nil && m.root != nil {
+		root, size = m.root, m.size
+	}
+
+	root, added := root.set(hashKey(key), 0, key, v)
+	if added {
+		size++
+	}
+
+	return &persistentMap{root: root, size: size}
+}
+
+func (m *persistentMap) delete(key string) (*persistentMap, bool) {
+	if m == nil || m.root == nil {
+		return m, false
+	}
+
+	root, removed := m.root.delete(hashKey(key), 0, key)
+	if !removed {
+		return m, false
+	}
+
+	return &persistentMap{root: root, size: m.size - 1}, true
+}
+
+func (m *persistentMap) each(fn func(key string, v Value)) {
+	if m != nil && m.root != nil {
+		m.root.each(fn)
+	}
+}
+
+func (n *hamtNode) set(h uint32, shift uint, key string, v Value) (*hamtNode, bool) {
+	entry := hamtEntry{hash: h, key: key, value: v}
+
+	if shift >= hamtDepth {
+		for i, e := range n.entries {
+			if e.key == key {
+				return n.replace(i, entry), false
+			}
+		}
+
+		return &hamtNode{entries: append(n.copyEntries(), entry)}, true
+	}
+
+	bit := uint32(1) << ((h >> shift) & hamtMask)
+	idx := bits.OnesCount32(n.bitmap & (bit - 1))
+
+	if n.bitmap&bit == 0 {
+		entries := make([]hamtEntry, 0, len(n.entries)+1)
+		entries = append(entries, n.entries[:idx]...)
+		entries = append(entries, entry)
+		entries = append(entries, n.entries[idx:]...)
+		return &hamtNode{bitmap: n.bitmap | bit, entries: entries}, true
+	}
+
+	e := n.entries[idx]
+	if e.node != nil {
+		child, added := e.node.set(h, shift+hamtBits, key, v)
+		return n.replace(idx, hamtEntry{node: child}), added
+	}
+
+	if e.key == key {
+		return n.replace(idx, entry), false
+	}
+
+	child, _ := (&hamtNode{}).set(e.hash, shift+hamtBits, e.key, e.value)
+	child, _ = child.set(h, shift+hamtBits, key, v)
+	return n.replace(idx, hamtEntry{node: child}), true
+}
+
+func (n *hamtNode) delete(h uint32, shift uint, key string) (*hamtNode, bool) {
+	if shift >= hamtDepth {
+		for i, e := range n.entries {
+			if e.key == key {
+				return n.remove(i, 0), true
+			}
+		}
+		return n, false
+	}
+
+	bit := uint32(1) << ((h >> shift) & hamtMask)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	idx := bits.OnesCount32(n.bitmap & (bit - 1))
+	e := n.entries[idx]
+	if e.node == nil {
+		if e.key != key {
+			return n, false
+		}
+		return n.remove(idx, bit), true
+	}
+
+	child, removed := e.node.delete(h, shift+hamtBits, key)
+	if !removed {
+		return n, false
+	}
+
+	if len(child.entries) == 0 {
+		return n.remove(idx, bit), true
+	}
+	return n.replace(idx, hamtEntry{node: child}), true
+}
+
+func (n *hamtNode) each(fn func(key string, v Value)) {
+	for _, e := range n.entries {
+		if e.node != nil {
+			e.node.each(fn)
+		} else {
+			fn(e.key, e.value)
+		}
+	}
+}
+
+func (n *hamtNode) replace(idx int, e hamtEntry) *hamtNode {
+	entries := n.copyEntries()
+	entries[idx] = e
+	return &hamtNode{bitmap: n.bitmap, entries: entries}
+}
+
+func (n *hamtNode) remove(idx int, bit uint32) *hamtNode {
+	entries := make([]hamtEntry, 0, len(n.entries)-1)
+	entries = append(entries, n.entries[:idx]...)
+	entries = append(entries, n.entries[idx+1:]...)
+	return &hamtNode{bitmap: n.bitmap &^ bit, entries: entries}
+}
+
+func (n *hamtNode) copyEntries() []hamtEntry {
+	entries := make([]hamtEntry, len(n.entries))
+	copy(entries, n.entries)
+	return entries
+}
+
+// hashKey returns 32-bit FNV-1a hash of the key.
+func hashKey(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
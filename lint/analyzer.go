@@ -0,0 +1,666 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spy16/sabre"
+)
+
+type analyzer struct {
+	linter *Linter
+	file   string
+	spans  *sabre.SourceMap
+	defs   map[string]*fnInfo
+	issues []Issue
+}
+
+func (a *analyzer) report(span sabre.Span, severity Severity, rule, msg string) {
+	if a.linter.disabled[rule] {
+		return
+	}
+
+	a.issues = append(a.issues, Issue{
+		Span:     span,
+		Severity: severity,
+		Rule:     rule,
+		Message:  msg,
+	})
+}
+
+func (a *analyzer) analyzeModule(mod sabre.Module) {
+	for _, form := range mod {
+		a.collectDefs(form)
+	}
+
+	for i, form := range mod {
+		a.analyze(form, nil, false, a.elemSpan(mod, i, sabre.Span{File: a.file}))
+	}
+}
+
+// collectDefs records all the names defined using 'def' so that references
+// to them are resolved irrespective of the order of definition.
+func (a *analyzer) collectDefs(form sabre.Value) {
+	switch f := form.(type) {
+	case *sabre.List:
+		name := a.specialName(f, nil)
+		if name == "quote" || name == "syntax-quote" {
+			return
+		}
+
+		if name == "def" && len(f.Values) == 3 {
+			if sym, isSymbol := f.Values[1].(sabre.Symbol); isSymbol {
+				a.defs[sym.Value] = a.fnLiteralInfo(f.Values[2])
+			}
+		}
+
+		for _, v := range f.Values {
+			a.collectDefs(v)
+		}
+
+	case sabre.Vector:
+		for _, v := range f.Values {
+			a.collectDefs(v)
+		}
+	}
+}
+
+func (a *analyzer) analyze(form sabre.Value, env *frame, tail bool, near sabre.Span) {
+	switch f := form.(type) {
+	case sabre.Symbol:
+		a.resolve(f, env, near)
+
+	case *sabre.List:
+		a.analyzeList(f, env, tail, near)
+
+	case sabre.Vector:
+		span := a.spanOf(f, near)
+		for i, v := range f.Values {
+			a.analyze(v, env, false, a.elemSpan(f, i, span))
+		}
+
+	case sabre.Set:
+		span := a.spanOf(f, near)
+		for i, v := range f.Values {
+			a.analyze(v, env, false, a.elemSpan(f, i, span))
+		}
+
+	case *sabre.HashMap:
+		span := a.spanOf(f, near)
+		for k, v := range f.Data {
+			a.analyze(k, env, false, span)
+			a.analyze(v, env, false, span)
+		}
+	}
+}
+
+func (a *analyzer) resolve(sym sabre.Symbol, env *frame, near sabre.Span) {
+	name := symbolRoot(sym.Value)
+	if local := env.lookup(name); local != nil {
+		local.used = true
+		return
+	}
+
+	if _, found := a.defs[name]; found {
+		return
+	}
+
+	v, err := a.linter.scope.Resolve(name)
+	if err != nil {
+		a.report(a.spanOf(sym, near), Error, RuleUnresolved,
+			fmt.Sprintf("unable to resolve symbol '%s'", sym.Value))
+		return
+	}
+
+	if _, isSpecial := v.(sabre.SpecialForm); isSpecial {
+		a.report(a.spanOf(sym, near), Error, RuleSpecialForm,
+			fmt.Sprintf("can't take value of special form '%s'", sym.Value))
+	} else if mfn, isMultiFn := v.(sabre.MultiFn); isMultiFn && mfn.IsMacro {
+		a.report(a.spanOf(sym, near), Error, RuleSpecialForm,
+			fmt.Sprintf("can't take value of macro '%s'", sym.Value))
+	}
+}
+
+func (a *analyzer) analyzeList(list *sabre.List, env *frame, tail bool, near sabre.Span) {
+	if len(list.Values) == 0 {
+		return
+	}
+
+	span := a.spanOf(list, near)
+	if name := a.specialName(list, env); name != "" {
+		a.analyzeSpecial(name, list, env, tail, span)
+		return
+	}
+
+	args := list.Values[1:]
+	sym, isSymbol := list.Values[0].(sabre.Symbol)
+	if isSymbol && env.lookup(symbolRoot(sym.Value)) == nil {
+		if mfn, isMultiFn := a.global(sym.Value).(sabre.MultiFn); isMultiFn && mfn.IsMacro {
+			// arguments of a macro invocation are not analyzed since their
+			// meaning is decided by the expansion.
+			a.checkArity(sym.Value, multiFnInfo(mfn), len(args), span)
+			return
+		}
+	}
+
+	a.analyze(list.Values[0], env, false, span)
+	for i, arg := range args {
+		a.analyze(arg, env, false, a.elemSpan(list, i+1, span))
+	}
+
+	if isSymbol && env.lookup(symbolRoot(sym.Value)) == nil {
+		a.checkArity(sym.Value, a.fnInfo(sym.Value), len(args), span)
+	}
+}
+
+func (a *analyzer) analyzeSpecial(name string, list *sabre.List, env *frame, tail bool, span sabre.Span) {
+	head := list.Values[0].String()
+	args := list.Values[1:]
+
+	switch name {
+	case "fn*", "macro*":
+		a.analyzeFn(head, list, env, span)
+
+	case "let":
+		a.analyzeLet(head, list, env, tail, span)
+
+	case "do":
+		a.analyzeBody(list, 1, env, tail, span)
+
+	case "if":
+		if len(args) != 2 && len(args) != 3 {
+			a.report(span, Error, RuleSpecialForm,
+				fmt.Sprintf("%s requires 2 or 3 argument(s), got %d", head, len(args)))
+		}
+		for i, arg := range args {
+			a.analyze(arg, env, tail && i > 0, a.elemSpan(list, i+1, span))
+		}
+
+	case "def":
+		if len(args) != 2 {
+			a.report(span, Error, RuleSpecialForm,
+				fmt.Sprintf("%s requires exactly 2 argument(s), got %d", head, len(args)))
+			return
+		}
+
+		if _, isSymbol := args[0].(sabre.Symbol); !isSymbol {
+			a.report(a.elemSpan(list, 1, span), Error, RuleSpecialForm,
+				fmt.Sprintf("first argument to %s must be a symbol", head))
+		}
+		a.analyze(args[1], env, false, a.elemSpan(list, 2, span))
+
+	case "quote":
+		if len(args) != 1 {
+			a.report(span, Error, RuleSpecialForm,
+				fmt.Sprintf("%s requires exactly 1 argument(s), got %d", head, len(args)))
+		}
+
+	case "syntax-quote":
+		for _, arg := range args {
+			a.analyzeUnquotes(arg, env, span)
+		}
+
+	case "recur":
+		method := env.method()
+		if method == nil {
+			a.report(span, Error, RuleRecur, "recur must be used inside a function body")
+		} else if !tail {
+			a.report(span, Error, RuleRecur, "recur can only be used in tail position")
+		} else if !method.accepts(len(args)) {
+			a.report(span, Error, RuleArity,
+				fmt.Sprintf("recur expects %s argument(s), got %d", method, len(args)))
+		}
+
+		for i, arg := range args {
+			a.analyze(arg, env, false, a.elemSpan(list, i+1, span))
+		}
+
+	default:
+		for i, arg := range args {
+			a.analyze(arg, env, false, a.elemSpan(list, i+1, span))
+		}
+	}
+}
+
+func (a *analyzer) analyzeFn(head string, list *sabre.List, env *frame, span sabre.Span) {
+	forms, offset := list.Values[1:], 1
+	if _, isName := firstOf(forms).(sabre.Symbol); isName {
+		forms, offset = forms[1:], 2
+	}
+
+	if len(forms) == 0 {
+		a.report(span, Error, RuleSpecialForm, fmt.Sprintf("%s requires an argument vector", head))
+		return
+	}
+
+	if _, isList := forms[0].(*sabre.List); !isList {
+		a.analyzeMethod(head, list, offset, env, span)
+		return
+	}
+
+	for i, form := range forms {
+		methodSpan := a.elemSpan(list, i+offset, span)
+		method, isList := form.(*sabre.List)
+		if !isList {
+			a.report(methodSpan, Error, RuleSpecialForm,
+				fmt.Sprintf("%s method must be a list of argument vector and body", head))
+			continue
+		}
+
+		if len(method.Values) == 0 {
+			a.report(a.spanOf(method, methodSpan), Error, RuleSpecialForm,
+				fmt.Sprintf("%s requires an argument vector", head))
+			continue
+		}
+		a.analyzeMethod(head, method, 0, env, a.spanOf(method, methodSpan))
+	}
+}
+
+// analyzeMethod analyzes a function method defined by the argument vector
+// at index 'at' in the list followed by the body forms.
+func (a *analyzer) analyzeMethod(head string, list *sabre.List, at int, env *frame, span sabre.Span) {
+	vecSpan := a.elemSpan(list, at, span)
+	vec, isVector := list.Values[at].(sabre.Vector)
+	if !isVector {
+		a.report(vecSpan, Error, RuleSpecialForm,
+			fmt.Sprintf("%s argument spec must be a vector of symbols", head))
+		return
+	}
+
+	fr := newFrame(env)
+	fr.fn = &arity{}
+
+	for i, v := range vec.Values {
+		sym, isSymbol := v.(sabre.Symbol)
+		if !isSymbol {
+			a.report(a.elemSpan(vec, i, vecSpan), Error, RuleSpecialForm,
+				fmt.Sprintf("%s arguments must be symbols, not '%s'", head, v))
+			continue
+		}
+
+		if sym.Value == "&" {
+			if i != len(vec.Values)-2 {
+				a.report(a.elemSpan(vec, i, vecSpan), Error, RuleSpecialForm,
+					"expecting exactly one symbol after '&'")
+			}
+			fr.fn.variadic = true
+			continue
+		}
+
+		if !fr.fn.variadic {
+			fr.fn.args++
+		}
+		a.bind(fr, sym, a.elemSpan(vec, i, vecSpan))
+	}
+
+	a.analyzeBody(list, at+1, fr, true, span)
+	a.closeFrame(fr)
+}
+
+func (a *analyzer) analyzeLet(head string, list *sabre.List, env *frame, tail bool, span sabre.Span) {
+	if len(list.Values) < 2 {
+		a.report(span, Error, RuleSpecialForm, fmt.Sprintf("%s requires a bindings vector", head))
+		return
+	}
+
+	vecSpan := a.elemSpan(list, 1, span)
+	vec, isVector := list.Values[1].(sabre.Vector)
+	if !isVector {
+		a.report(vecSpan, Error, RuleSpecialForm,
+			fmt.Sprintf("first argument to %s must be a bindings vector", head))
+		a.analyzeBody(list, 2, env, tail, span)
+		return
+	}
+
+	if len(vec.Values)%2 != 0 {
+		a.report(a.spanOf(vec, vecSpan), Error, RuleSpecialForm,
+			fmt.Sprintf("%s bindings must contain an even number of forms, got %d",
+				head, len(vec.Values)))
+	}
+
+	fr := newFrame(env)
+	for i := 0; i+1 < len(vec.Values); i += 2 {
+		a.analyze(vec.Values[i+1], fr, false, a.elemSpan(vec, i+1, vecSpan))
+
+		sym, isSymbol := vec.Values[i].(sabre.Symbol)
+		if !isSymbol {
+			a.report(a.elemSpan(vec, i, vecSpan), Error, RuleSpecialForm,
+				fmt.Sprintf("%s binding names must be symbols, not '%s'", head, vec.Values[i]))
+			continue
+		}
+		a.bind(fr, sym, a.elemSpan(vec, i, vecSpan))
+	}
+
+	a.analyzeBody(list, 2, fr, tail, span)
+	a.closeFrame(fr)
+}
+
+// analyzeBody analyzes the forms of the list starting at 'from' as a body
+// where the last form is in tail position if the body is.
+func (a *analyzer) analyzeBody(list *sabre.List, from int, env *frame, tail bool, span sabre.Span) {
+	for i := from; i < len(list.Values); i++ {
+		isLast := i == len(list.Values)-1
+		a.analyze(list.Values[i], env, tail && isLast, a.elemSpan(list, i, span))
+	}
+}
+
+func (a *analyzer) analyzeUnquotes(form sabre.Value, env *frame, near sabre.Span) {
+	switch f := form.(type) {
+	case *sabre.List:
+		span := a.spanOf(f, near)
+		if sym, isSymbol := firstOf(f.Values).(sabre.Symbol); isSymbol && sym.Value == "unquote" {
+			for i, v := range f.Values[1:] {
+				a.analyze(v, env, false, a.elemSpan(f, i+1, span))
+			}
+			return
+		}
+
+		for _, v := range f.Values {
+			a.analyzeUnquotes(v, env, span)
+		}
+
+	case sabre.Vector:
+		for _, v := range f.Values {
+			a.analyzeUnquotes(v, env, a.spanOf(f, near))
+		}
+
+	case sabre.Set:
+		for _, v := range f.Values {
+			a.analyzeUnquotes(v, env, a.spanOf(f, near))
+		}
+
+	case *sabre.HashMap:
+		for k, v := range f.Data {
+			a.analyzeUnquotes(k, env, a.spanOf(f, near))
+			a.analyzeUnquotes(v, env, a.spanOf(f, near))
+		}
+	}
+}
+
+func (a *analyzer) bind(fr *frame, sym sabre.Symbol, near sabre.Span) {
+	span := a.spanOf(sym, near)
+
+	if prev, exists := fr.locals[sym.Value]; exists {
+		// rebinding within the same frame (e.g., in let*) replaces the
+		// previous binding.
+		a.checkUsed(prev)
+	} else if fr.parent.lookup(sym.Value) != nil {
+		a.report(span, Warning, RuleShadowed,
+			fmt.Sprintf("binding '%s' shadows an outer binding", sym.Value))
+	}
+
+	l := &local{name: sym.Value, span: span}
+	fr.locals[sym.Value] = l
+	fr.order = append(fr.order, l)
+}
+
+func (a *analyzer) closeFrame(fr *frame) {
+	for _, l := range fr.order {
+		if fr.locals[l.name] == l {
+			a.checkUsed(l)
+		}
+	}
+}
+
+func (a *analyzer) checkUsed(l *local) {
+	if l.used || strings.HasPrefix(l.name, "_") || strings.HasPrefix(l.name, "%") {
+		return
+	}
+
+	a.report(l.span, Warning, RuleUnused, fmt.Sprintf("unused binding '%s'", l.name))
+}
+
+func (a *analyzer) checkArity(name string, info *fnInfo, argc int, span sabre.Span) {
+	if info == nil || info.accepts(argc) {
+		return
+	}
+
+	a.report(span, Error, RuleArity,
+		fmt.Sprintf("wrong number of args (%d) passed to '%s', expecting %s", argc, name, info))
+}
+
+// specialName returns the name of the special form invoked by the list or
+// empty string if the list is not a special form invocation.
+func (a *analyzer) specialName(list *sabre.List, env *frame) string {
+	sym, isSymbol := firstOf(list.Values).(sabre.Symbol)
+	if !isSymbol || env.lookup(sym.Value) != nil {
+		return ""
+	}
+
+	special, isSpecial := a.global(sym.Value).(sabre.SpecialForm)
+	if !isSpecial {
+		return ""
+	}
+	return special.Name
+}
+
+// global returns the value bound to the symbol in the root scope. Returns
+// nil if the symbol is not bound or is defined in the source being analyzed.
+func (a *analyzer) global(name string) sabre.Value {
+	if _, found := a.defs[name]; found {
+		return nil
+	}
+
+	v, err := a.linter.scope.Resolve(name)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// fnInfo returns arity information of the function bound to the name either
+// in the source or in the root scope. Returns nil if not known.
+func (a *analyzer) fnInfo(name string) *fnInfo {
+	if info, found := a.defs[name]; found {
+		return info
+	}
+
+	switch fn := a.global(name).(type) {
+	case sabre.MultiFn:
+		return multiFnInfo(fn)
+
+	case *sabre.Fn:
+		// functions with a custom Func and no argument names do not declare
+		// their arity.
+		if fn.Func != nil && len(fn.Args) == 0 && !fn.Variadic {
+			return nil
+		}
+		return &fnInfo{methods: []arity{fnArity(*fn)}}
+	}
+
+	return nil
+}
+
+// fnLiteralInfo returns arity information of a (fn* ...) form.
+func (a *analyzer) fnLiteralInfo(form sabre.Value) *fnInfo {
+	list, isList := form.(*sabre.List)
+	if !isList || a.specialName(list, nil) != "fn*" {
+		return nil
+	}
+
+	forms := list.Values[1:]
+	if _, isName := firstOf(forms).(sabre.Symbol); isName {
+		forms = forms[1:]
+	}
+
+	if _, isVector := firstOf(forms).(sabre.Vector); isVector {
+		forms = []sabre.Value{&sabre.List{Values: forms}}
+	}
+
+	info := &fnInfo{}
+	for _, form := range forms {
+		method, isList := form.(*sabre.List)
+		if !isList {
+			return nil
+		}
+
+		vec, isVector := firstOf(method.Values).(sabre.Vector)
+		if !isVector {
+			return nil
+		}
+
+		var ar arity
+		for _, v := range vec.Values {
+			if sym, isSymbol := v.(sabre.Symbol); isSymbol && sym.Value == "&" {
+				ar.variadic = true
+				break
+			}
+			ar.args++
+		}
+		info.methods = append(info.methods, ar)
+	}
+
+	return info
+}
+
+func (a *analyzer) spanOf(form sabre.Value, near sabre.Span) sabre.Span {
+	if span, found := a.spans.Span(form); found {
+		return span
+	}
+
+	if p, hasPos := form.(interface {
+		GetPos() (file string, line, col int)
+	}); hasPos {
+		file, line, col := p.GetPos()
+		if line > 0 {
+			loc := sabre.Location{Line: line, Column: col}
+			return sabre.Span{File: file, Start: loc, End: loc}
+		}
+	}
+
+	return near
+}
+
+func (a *analyzer) elemSpan(container sabre.Value, i int, near sabre.Span) sabre.Span {
+	if span, found := a.spans.ElementSpan(container, i); found {
+		return span
+	}
+
+	var vals []sabre.Value
+	switch c := container.(type) {
+	case *sabre.List:
+		vals = c.Values
+	case sabre.Vector:
+		vals = c.Values
+	case sabre.Set:
+		vals = c.Values
+	case sabre.Module:
+		vals = c
+	}
+
+	if i < len(vals) {
+		return a.spanOf(vals[i], near)
+	}
+	return near
+}
+
+type fnInfo struct {
+	methods []arity
+}
+
+func (fi *fnInfo) accepts(argc int) bool {
+	for _, m := range fi.methods {
+		if m.accepts(argc) {
+			return true
+		}
+	}
+	return len(fi.methods) == 0
+}
+
+func (fi *fnInfo) String() string {
+	var parts []string
+	for _, m := range fi.methods {
+		parts = append(parts, m.String())
+	}
+	return strings.Join(parts, " or ")
+}
+
+type arity struct {
+	args     int
+	variadic bool
+}
+
+func (ar arity) accepts(argc int) bool {
+	if ar.variadic {
+		return argc >= ar.args
+	}
+	return argc == ar.args
+}
+
+func (ar arity) String() string {
+	if ar.variadic {
+		return fmt.Sprintf("at-least %d", ar.args)
+	}
+	return fmt.Sprintf("%d", ar.args)
+}
+
+func fnArity(fn sabre.Fn) arity {
+	if fn.Variadic && len(fn.Args) > 0 {
+		return arity{args: len(fn.Args) - 1, variadic: true}
+	}
+	return arity{args: len(fn.Args), variadic: fn.Variadic}
+}
+
+func multiFnInfo(mfn sabre.MultiFn) *fnInfo {
+	info := &fnInfo{}
+	for _, m := range mfn.Methods {
+		info.methods = append(info.methods, fnArity(m))
+	}
+	return info
+}
+
+type local struct {
+	name string
+	span sabre.Span
+	used bool
+}
+
+// frame represents a lexical scope introduced by fn* or let*.
+type frame struct {
+	parent *frame
+	locals map[string]*local
+	order  []*local
+	fn     *arity
+}
+
+func newFrame(parent *frame) *frame {
+	return &frame{
+		parent: parent,
+		locals: map[string]*local{},
+	}
+}
+
+func (fr *frame) lookup(name string) *local {
+	for f := fr; f != nil; f = f.parent {
+		if l, found := f.locals[name]; found {
+			return l
+		}
+	}
+	return nil
+}
+
+// method returns the arity of the innermost function the frame belongs to.
+func (fr *frame) method() *arity {
+	for f := fr; f != nil; f = f.parent {
+		if f.fn != nil {
+			return f.fn
+		}
+	}
+	return nil
+}
+
+// symbolRoot returns the name that must be resolved for the symbol. For
+// member access symbols (e.g., 'coll.Conj'), this is the target name.
+func symbolRoot(name string) string {
+	if name == "." {
+		return name
+	}
+	return strings.Split(name, ".")[0]
+}
+
+func firstOf(vals []sabre.Value) sabre.Value {
+	if len(vals) == 0 {
+		return nil
+	}
+	return vals[0]
+}
@@ -0,0 +1,135 @@
+// Package lint provides static analysis of sabre source. Forms are analyzed
+// without evaluation against a root scope so that symbols bound in the scope
+// (e.g., Go functions exposed using ValueOf) are resolved and invocations of
+// known functions are checked for arity.
+package lint
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spy16/sabre"
+)
+
+// Rules reported by the linter.
+const (
+	RuleSyntax      = "syntax"
+	RuleUnresolved  = "unresolved-symbol"
+	RuleArity       = "arity"
+	RuleRecur       = "recur-position"
+	RuleSpecialForm = "special-form"
+	RuleUnused      = "unused-local"
+	RuleShadowed    = "shadowed-binding"
+)
+
+// Severity represents the severity of an issue.
+type Severity int
+
+// Severity levels of issues.
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue represents a problem found in the source.
+type Issue struct {
+	Span     sabre.Span
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)",
+		issue.Span.Position(), issue.Severity, issue.Message, issue.Rule)
+}
+
+// Option implementations can be provided to New() to configure the linter.
+type Option func(l *Linter)
+
+// WithDisabled disables reporting of issues for the given rules.
+func WithDisabled(rules ...string) Option {
+	return func(l *Linter) {
+		for _, rule := range rules {
+			l.disabled[rule] = true
+		}
+	}
+}
+
+// New returns a linter that analyzes forms against the given root scope.
+// If scope is nil, sabre.New() is used.
+func New(scope sabre.Scope, opts ...Option) *Linter {
+	if scope == nil {
+		scope = sabre.New()
+	}
+
+	l := &Linter{
+		scope:    scope,
+		disabled: map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Linter analyzes sabre source and reports issues.
+type Linter struct {
+	scope    sabre.Scope
+	disabled map[string]bool
+}
+
+// LintFile reads and analyzes the file.
+func (l *Linter) LintFile(path string) ([]Issue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return l.Lint(f), nil
+}
+
+// Lint reads all forms from r using sabre.Reader and analyzes them. Syntax
+// errors are reported as issues and do not stop analysis of the remaining
+// forms. Issues are sorted by their position.
+func (l *Linter) Lint(r io.Reader) []Issue {
+	rd := sabre.NewReader(r)
+	mod, readErrs := rd.AllWithErrors()
+
+	a := &analyzer{
+		linter: l,
+		file:   rd.File,
+		spans:  rd.SourceMap(),
+		defs:   map[string]*fnInfo{},
+	}
+
+	for _, err := range readErrs {
+		loc := sabre.Location{Line: err.Line, Column: err.Column}
+		a.report(sabre.Span{File: err.File, Start: loc, End: loc},
+			Error, RuleSyntax, fmt.Sprint(err.Cause))
+	}
+
+	a.analyzeModule(mod)
+
+	sort.SliceStable(a.issues, func(i, j int) bool {
+		pi, pj := a.issues[i].Span.Start, a.issues[j].Span.Start
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+
+	return a.issues
+}
@@ -0,0 +1,146 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/lint"
+)
+
+func TestLinter_Lint(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "Clean",
+			src: `(def add (fn* add [a b] (+ a b)))
+			      (def sum (fn* [coll acc]
+			                 (if (empty? coll) acc (recur (rest coll) (+ acc (first coll))))))
+			      (add 1 (sum [1 2] 0))
+			      (let* [x 1 x (inc x)] x)
+			      '(unknown symbols are fine in quotes)
+			      #(inc %2)`,
+			want: nil,
+		},
+		{
+			name: "Unresolved",
+			src:  "(foo 1)\n(inc bar.Baz)",
+			want: []string{
+				"1:2: error: unable to resolve symbol 'foo' (unresolved-symbol)",
+				"2:6: error: unable to resolve symbol 'bar.Baz' (unresolved-symbol)",
+			},
+		},
+		{
+			name: "GoFuncArity",
+			src:  "(inc 1 2)\n(+)\n(str)\n(re-find #\"a\")",
+			want: []string{
+				"1:1: error: wrong number of args (2) passed to 'inc', expecting 1 (arity)",
+				"4:1: error: wrong number of args (1) passed to 're-find', expecting 2 (arity)",
+			},
+		},
+		{
+			name: "MultiFnArity",
+			src: `(def f (fn* ([a] a) ([_a b & _more] b)))
+(f)
+(f 1 2 3)
+(twice 1 2)`,
+			want: []string{
+				"2:1: error: wrong number of args (0) passed to 'f', expecting 1 or at-least 2 (arity)",
+				"4:1: error: wrong number of args (2) passed to 'twice', expecting 1 (arity)",
+			},
+		},
+		{
+			name: "Recur",
+			src: `(recur 1)
+(fn* [a] (do (recur a) a))
+(fn* [a] (recur a 2))
+(fn* [a] (let* [b a] (if b (recur b) a)))`,
+			want: []string{
+				"1:1: error: recur must be used inside a function body (recur-position)",
+				"2:14: error: recur can only be used in tail position (recur-position)",
+				"3:10: error: recur expects 1 argument(s), got 2 (arity)",
+			},
+		},
+		{
+			name: "LetBindings",
+			src:  "(let* [a 1 b] a)\n(let* [1 2])",
+			want: []string{
+				"1:7: error: let* bindings must contain an even number of forms, got 3 (special-form)",
+				"2:8: error: let* binding names must be symbols, not '1' (special-form)",
+			},
+		},
+		{
+			name: "UnusedAndShadowed",
+			src:  "(fn* [a _b] (let* [c 1 a 2] a))",
+			want: []string{
+				"1:7: warning: unused binding 'a' (unused-local)",
+				"1:20: warning: unused binding 'c' (unused-local)",
+				"1:24: warning: binding 'a' shadows an outer binding (shadowed-binding)",
+			},
+		},
+		{
+			name: "SpecialFormMisuse",
+			src:  "(if true)\n(inc if)\n(def 1 2)",
+			want: []string{
+				"1:1: error: if requires 2 or 3 argument(s), got 1 (special-form)",
+				"2:6: error: can't take value of special form 'if' (special-form)",
+				"3:6: error: first argument to def must be a symbol (special-form)",
+			},
+		},
+		{
+			name: "SyntaxErrorsAndRecovery",
+			src:  "(foo]\n(bar)",
+			want: []string{
+				"1:5: error: unmatched delimiter ']' (syntax)",
+				"2:2: error: unable to resolve symbol 'bar' (unresolved-symbol)",
+			},
+		},
+		{
+			name: "SyntaxQuote",
+			src:  "`(a b ~c ~(inc d))",
+			want: []string{
+				"1:8: error: unable to resolve symbol 'c' (unresolved-symbol)",
+				"1:16: error: unable to resolve symbol 'd' (unresolved-symbol)",
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+			scope.BindGo("+", func(nums ...sabre.Int64) sabre.Int64 { return 0 })
+			scope.BindGo("empty?", func(v sabre.Value) bool { return false })
+			scope.BindGo("first", func(v sabre.Value) sabre.Value { return v })
+			scope.BindGo("rest", func(v sabre.Value) sabre.Value { return v })
+			_, _ = sabre.ReadEvalStr(scope, "(def twice (fn* [x] x))")
+
+			issues := lint.New(scope).Lint(strings.NewReader(tt.src))
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, strings.TrimPrefix(issue.String(), "<string>:"))
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Lint() got:\n%s\nwant:\n%s",
+					strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestWithDisabled(t *testing.T) {
+	t.Parallel()
+
+	l := lint.New(nil, lint.WithDisabled(lint.RuleUnused, lint.RuleUnresolved))
+	issues := l.Lint(strings.NewReader("(let* [a 1] (foo))"))
+	if len(issues) != 0 {
+		t.Errorf("Lint() expected no issues, got %v", issues)
+	}
+}
@@ -0,0 +1,325 @@
+package lsp
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/cst"
+)
+
+// document is a source file known to the server. Documents are either opened
+// by the client or loaded from the workspace directory.
+type document struct {
+	uri   string
+	text  string
+	open  bool
+	lines []int // byte offsets of the start of each line.
+	root  *cst.Node
+	defs  []definition
+}
+
+// definition represents a (def name value) form at the top-level of a
+// document.
+type definition struct {
+	uri  string
+	name string
+	span sabre.Span
+	doc  string
+	sigs []string
+}
+
+func newDocument(uri, text string) *document {
+	doc := &document{
+		uri:   uri,
+		text:  text,
+		lines: []int{0},
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			doc.lines = append(doc.lines, i+1)
+		}
+	}
+
+	// root is nil if the document has syntax errors. symbolAt() falls back
+	// to scanning the text in that case.
+	doc.root, _ = cst.Parse(text)
+	doc.defs = doc.collectDefs()
+	return doc
+}
+
+// collectDefs returns the top-level definitions in the document. Comment
+// lines immediately preceding a definition are used as its documentation.
+func (doc *document) collectDefs() []definition {
+	rd := sabre.NewReader(strings.NewReader(doc.text))
+	mod, _ := rd.AllWithErrors()
+	sm := rd.SourceMap()
+
+	var defs []definition
+	for _, form := range mod {
+		list, isList := form.(*sabre.List)
+		if !isList || len(list.Values) < 2 {
+			continue
+		}
+
+		head, isSymbol := list.Values[0].(sabre.Symbol)
+		if !isSymbol || head.Value != "def" {
+			continue
+		}
+
+		name, isSymbol := list.Values[1].(sabre.Symbol)
+		if !isSymbol {
+			continue
+		}
+
+		span, found := sm.ElementSpan(list, 1)
+		if !found {
+			continue
+		}
+
+		def := definition{
+			uri:  doc.uri,
+			name: name.Value,
+			span: span,
+			doc:  doc.commentsAbove(span.Start.Line),
+		}
+		if len(list.Values) > 2 {
+			def.sigs = signatures(name.Value, list.Values[2])
+		}
+		defs = append(defs, def)
+	}
+
+	return defs
+}
+
+// commentsAbove returns the text of consecutive comment lines immediately
+// above the given line (1-based).
+func (doc *document) commentsAbove(line int) string {
+	var comments []string
+	for l := line - 1; l >= 1; l-- {
+		text := strings.TrimSpace(doc.line(l - 1))
+		if !strings.HasPrefix(text, ";") {
+			break
+		}
+		comments = append([]string{strings.TrimSpace(strings.TrimLeft(text, ";"))}, comments...)
+	}
+
+	return strings.Join(comments, "\n")
+}
+
+// signatures returns the call signatures of the (fn* ...) or (macro* ...)
+// form bound by a definition.
+func signatures(name string, form sabre.Value) []string {
+	list, isList := form.(*sabre.List)
+	if !isList || len(list.Values) < 2 {
+		return nil
+	}
+
+	head, isSymbol := list.Values[0].(sabre.Symbol)
+	if !isSymbol || (head.Value != "fn*" && head.Value != "macro*") {
+		return nil
+	}
+
+	methods := list.Values[1:]
+	if _, isSymbol := methods[0].(sabre.Symbol); isSymbol {
+		methods = methods[1:]
+	}
+
+	if len(methods) > 0 {
+		if _, isVector := methods[0].(sabre.Vector); isVector {
+			methods = methods[:1]
+		}
+	}
+
+	var sigs []string
+	for _, method := range methods {
+		if l, isList := method.(*sabre.List); isList && len(l.Values) > 0 {
+			method = l.Values[0]
+		}
+
+		args, isVector := method.(sabre.Vector)
+		if !isVector {
+			continue
+		}
+
+		sig := name
+		for _, arg := range args.Values {
+			sig += " " + arg.String()
+		}
+		sigs = append(sigs, "("+sig+")")
+	}
+
+	return sigs
+}
+
+// line returns the text of the line (0-based) without the line terminator.
+func (doc *document) line(i int) string {
+	if i < 0 || i >= len(doc.lines) {
+		return ""
+	}
+
+	end := len(doc.text)
+	if i+1 < len(doc.lines) {
+		end = doc.lines[i+1]
+	}
+
+	return strings.TrimRight(doc.text[doc.lines[i]:end], "\r\n")
+}
+
+// offset converts the LSP position to a byte offset in the text. Positions
+// beyond the end of a line are clamped to the end of the line.
+func (doc *document) offset(pos position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+
+	if pos.Line >= len(doc.lines) {
+		return len(doc.text)
+	}
+
+	start := doc.lines[pos.Line]
+	line := doc.line(pos.Line)
+
+	units := 0
+	for i, r := range line {
+		if units >= pos.Character {
+			return start + i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+
+	return start + len(line)
+}
+
+// position converts the 1-based line and rune column of the location to an
+// LSP position which counts characters in UTF-16 code units.
+func (doc *document) position(loc sabre.Location) position {
+	line := loc.Line - 1
+	if line < 0 {
+		return position{}
+	}
+
+	units, col := 0, 1
+	for _, r := range doc.line(line) {
+		if col >= loc.Column {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+		col++
+	}
+
+	return position{Line: line, Character: units}
+}
+
+// positionAt converts the byte offset to an LSP position.
+func (doc *document) positionAt(offset int) position {
+	line := 0
+	for line+1 < len(doc.lines) && doc.lines[line+1] <= offset {
+		line++
+	}
+
+	prefix := doc.text[doc.lines[line]:offset]
+	return position{
+		Line:      line,
+		Character: len(utf16.Encode([]rune(prefix))),
+	}
+}
+
+func (doc *document) rangeOf(span sabre.Span) textRange {
+	end := span.End
+	if end.Line == 0 {
+		end = span.Start
+	}
+
+	return textRange{
+		Start: doc.position(span.Start),
+		End:   doc.position(end),
+	}
+}
+
+// symbolAt returns the symbol at the byte offset and its range. Returns
+// empty string if there is no symbol at the offset.
+func (doc *document) symbolAt(offset int) (string, textRange) {
+	if doc.root == nil {
+		start, end := doc.tokenAt(offset)
+		token := doc.text[start:end]
+		if !isSymbolToken(token) {
+			return "", textRange{}
+		}
+		return token, textRange{Start: doc.positionAt(start), End: doc.positionAt(end)}
+	}
+
+	node := leafAt(doc.root, offset)
+	if node == nil || node.Kind != cst.Symbol {
+		return "", textRange{}
+	}
+
+	return node.Text, doc.rangeOf(node.Span)
+}
+
+// prefixAt returns the start offset and text of the partial symbol that
+// ends at the offset.
+func (doc *document) prefixAt(offset int) (int, string) {
+	start := offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(doc.text[:start])
+		if isDelimiter(r) {
+			break
+		}
+		start -= size
+	}
+
+	return start, doc.text[start:offset]
+}
+
+// tokenAt returns the bounds of the token surrounding the byte offset.
+func (doc *document) tokenAt(offset int) (int, int) {
+	start, _ := doc.prefixAt(offset)
+
+	end := offset
+	for end < len(doc.text) {
+		r, size := utf8.DecodeRuneInString(doc.text[end:])
+		if isDelimiter(r) {
+			break
+		}
+		end += size
+	}
+
+	return start, end
+}
+
+// leafAt returns the innermost node without children whose span contains the
+// byte offset.
+func leafAt(node *cst.Node, offset int) *cst.Node {
+	for _, child := range node.Children {
+		if offset < child.Span.Start.Offset || offset > child.Span.End.Offset {
+			continue
+		}
+
+		if len(child.Children) == 0 && !child.IsContainer() {
+			return child
+		}
+
+		if leaf := leafAt(child, offset); leaf != nil {
+			return leaf
+		}
+	}
+
+	return nil
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()[]{}",;'`+"`~@^", r)
+}
+
+func isSymbolToken(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(token)
+	return !unicode.IsDigit(r) && !strings.ContainsRune(`:\#`, r)
+}
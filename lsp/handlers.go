@@ -0,0 +1,504 @@
+package lsp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/lint"
+)
+
+var extensions = []string{".lisp", ".sabre"}
+
+func (s *Server) initialize(p initializeParams) interface{} {
+	s.initialized = true
+
+	root := uriToPath(p.RootURI)
+	if root == "" {
+		root = p.RootPath
+	}
+	if root != "" {
+		s.loadWorkspace(root)
+	}
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    syncFull,
+			},
+			"hoverProvider":      true,
+			"definitionProvider": true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{"."},
+			},
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{"name": "sabre-lsp"},
+	}
+}
+
+// loadWorkspace loads all source files in the directory so that symbols
+// defined in them can be resolved by definition and completion requests.
+func (s *Server) loadWorkspace(root string) {
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isSourceFile(info.Name()) {
+			s.loadFile(pathToURI(path))
+		}
+		return nil
+	})
+}
+
+// loadFile reads the document identified by the uri from the file system.
+// Returns false if the file could not be read.
+func (s *Server) loadFile(uri string) bool {
+	path := uriToPath(uri)
+	if path == "" {
+		return false
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	s.documents[uri] = newDocument(uri, string(src))
+	return true
+}
+
+func (s *Server) didOpen(p didOpenParams) error {
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Text)
+	doc.open = true
+	s.documents[doc.uri] = doc
+	return s.publishAll()
+}
+
+func (s *Server) didChange(p didChangeParams) error {
+	doc, found := s.documents[p.TextDocument.URI]
+	if !found {
+		return nil
+	}
+
+	text := doc.text
+	for _, change := range p.ContentChanges {
+		if change.Range == nil {
+			text = change.Text
+			continue
+		}
+
+		cur := newDocument(doc.uri, text)
+		start, end := cur.offset(change.Range.Start), cur.offset(change.Range.End)
+		if end < start {
+			start, end = end, start
+		}
+		text = text[:start] + change.Text + text[end:]
+	}
+
+	doc = newDocument(doc.uri, text)
+	doc.open = true
+	s.documents[doc.uri] = doc
+	return s.publishAll()
+}
+
+func (s *Server) didClose(p didCloseParams) error {
+	uri := p.TextDocument.URI
+	if !s.loadFile(uri) {
+		delete(s.documents, uri)
+	}
+
+	err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: []diagnostic{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.publishAll()
+}
+
+// publishAll publishes diagnostics for all open documents. Since documents
+// can refer to definitions in other documents, a change in one document can
+// affect diagnostics of others.
+func (s *Server) publishAll() error {
+	uris := make([]string, 0, len(s.documents))
+	for uri, doc := range s.documents {
+		if doc.open {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: s.diagnostics(s.documents[uri]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diagnostics lints the document. Symbols defined in other documents are
+// bound in a child scope of the root scope so that they are not reported as
+// unresolved.
+func (s *Server) diagnostics(doc *document) []diagnostic {
+	scope := sabre.NewScope(s.scope)
+	for uri, other := range s.documents {
+		if uri == doc.uri {
+			continue
+		}
+
+		for _, def := range other.defs {
+			_ = scope.Bind(def.name, sabre.Nil{})
+		}
+	}
+
+	issues := lint.New(scope, s.lintOpts...).Lint(strings.NewReader(doc.text))
+
+	diags := make([]diagnostic, 0, len(issues))
+	for _, issue := range issues {
+		severity := severityWarning
+		if issue.Severity == lint.Error {
+			severity = severityError
+		}
+
+		diags = append(diags, diagnostic{
+			Range:    doc.rangeOf(issue.Span),
+			Severity: severity,
+			Code:     issue.Rule,
+			Source:   "sabre",
+			Message:  issue.Message,
+		})
+	}
+
+	return diags
+}
+
+func (s *Server) hover(p textDocumentPositionParams) *hover {
+	doc, found := s.documents[p.TextDocument.URI]
+	if !found {
+		return nil
+	}
+
+	name, rng := doc.symbolAt(doc.offset(p.Position))
+	if name == "" {
+		return nil
+	}
+
+	var sigs []string
+	var docs string
+	if def := s.lookupDef(name, doc.uri); def != nil {
+		sigs, docs = def.sigs, def.doc
+		if len(sigs) == 0 {
+			sigs = []string{"(def " + name + " ...)"}
+		}
+	} else {
+		v := s.resolve(name)
+		if v == nil {
+			return nil
+		}
+		sigs, docs = describe(name, v), s.docs[name]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```clojure\n" + strings.Join(sigs, "\n") + "\n```")
+	if docs != "" {
+		sb.WriteString("\n\n" + docs)
+	}
+
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: sb.String()},
+		Range:    &rng,
+	}
+}
+
+func (s *Server) definition(p textDocumentPositionParams) *location {
+	doc, found := s.documents[p.TextDocument.URI]
+	if !found {
+		return nil
+	}
+
+	name, _ := doc.symbolAt(doc.offset(p.Position))
+	if name == "" {
+		return nil
+	}
+
+	def := s.lookupDef(name, doc.uri)
+	if def == nil {
+		return nil
+	}
+
+	return &location{
+		URI:   def.uri,
+		Range: s.documents[def.uri].rangeOf(def.span),
+	}
+}
+
+func (s *Server) completion(p textDocumentPositionParams) []completionItem {
+	items := []completionItem{}
+
+	doc, found := s.documents[p.TextDocument.URI]
+	if !found {
+		return items
+	}
+
+	offset := doc.offset(p.Position)
+	start, prefix := doc.prefixAt(offset)
+	if prefix != "" && !isSymbolToken(prefix) {
+		return items
+	}
+
+	if dot := strings.LastIndex(prefix, "."); dot > 0 {
+		target := s.resolve(prefix[:dot])
+		if target == nil {
+			return items
+		}
+
+		rt := reflect.TypeOf(target)
+		if any, isAny := target.(sabre.Any); isAny && any.V.IsValid() {
+			rt = any.V.Type()
+		}
+
+		rng := textRange{Start: doc.positionAt(start + dot + 1), End: doc.positionAt(offset)}
+		for _, member := range sabre.Members(target) {
+			if !strings.HasPrefix(member, prefix[dot+1:]) {
+				continue
+			}
+
+			kind := completionField
+			if _, isMethod := rt.MethodByName(member); isMethod {
+				kind = completionMethod
+			}
+
+			items = append(items, completionItem{
+				Label:    member,
+				Kind:     kind,
+				TextEdit: &textEdit{Range: rng, NewText: member},
+			})
+		}
+		return items
+	}
+
+	rng := textRange{Start: doc.positionAt(start), End: doc.positionAt(offset)}
+	for name, v := range s.candidates() {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		items = append(items, completionItem{
+			Label:    name,
+			Kind:     completionKind(v),
+			Detail:   strings.Join(describe(name, v), " "),
+			TextEdit: &textEdit{Range: rng, NewText: name},
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items
+}
+
+func (s *Server) formatting(p formattingParams) ([]textEdit, error) {
+	doc, found := s.documents[p.TextDocument.URI]
+	if !found {
+		return nil, fmt.Errorf("document '%s' is not open", p.TextDocument.URI)
+	}
+
+	res, err := s.formatter.Source([]byte(doc.text))
+	if err != nil {
+		return nil, err
+	}
+
+	if string(res) == doc.text {
+		return []textEdit{}, nil
+	}
+
+	return []textEdit{
+		{
+			Range: textRange{
+				Start: position{},
+				End:   doc.positionAt(len(doc.text)),
+			},
+			NewText: string(res),
+		},
+	}, nil
+}
+
+// lookupDef finds the definition of the symbol. Definitions in the current
+// document take precedence over those in other documents.
+func (s *Server) lookupDef(name, current string) *definition {
+	uris := make([]string, 0, len(s.documents))
+	for uri := range s.documents {
+		if uri != current {
+			uris = append(uris, uri)
+		}
+	}
+	sort.Strings(uris)
+	uris = append([]string{current}, uris...)
+
+	for _, uri := range uris {
+		doc, found := s.documents[uri]
+		if !found {
+			continue
+		}
+
+		for i := range doc.defs {
+			if doc.defs[i].name == name {
+				return &doc.defs[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the value bound to the symbol in the root scope. Symbols
+// of the form 'target.Member' are resolved using member access. Returns nil
+// if the symbol cannot be resolved.
+func (s *Server) resolve(name string) sabre.Value {
+	if v, err := s.scope.Resolve(name); err == nil {
+		return v
+	}
+
+	if !strings.Contains(name, ".") {
+		return nil
+	}
+
+	v, err := sabre.Symbol{Value: name}.Eval(s.scope)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// candidates returns symbols that can be completed: bindings of the root
+// scope and its parents and definitions in all documents.
+func (s *Server) candidates() map[string]sabre.Value {
+	names := sabre.Bindings(s.scope)
+	for _, doc := range s.documents {
+		for _, def := range doc.defs {
+			if _, found := names[def.name]; !found {
+				names[def.name] = nil
+			}
+		}
+	}
+
+	return names
+}
+
+// describe returns the signatures or a short description of the value bound
+// to the symbol.
+func describe(name string, v sabre.Value) []string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+
+	case sabre.SpecialForm:
+		return []string{"(" + name + " ...) ; special form"}
+
+	case sabre.MultiFn:
+		var sigs []string
+		for _, method := range val.Methods {
+			sigs = append(sigs, call(name, method.String()))
+		}
+		if val.IsMacro && len(sigs) > 0 {
+			sigs[0] += " ; macro"
+		}
+		return sigs
+
+	case *sabre.Fn:
+		return []string{call(name, val.String())}
+
+	case sabre.Type:
+		return []string{name + " ; type " + val.T.String()}
+
+	case sabre.Any:
+		if val.V.IsValid() {
+			return []string{name + " ; " + val.V.Type().String()}
+		}
+	}
+
+	return []string{name + " ; " + sabre.PrStr(v)}
+}
+
+func call(name, args string) string {
+	args = strings.TrimSpace(strings.Trim(args, "()"))
+	if args == "" {
+		return "(" + name + ")"
+	}
+	return "(" + name + " " + args + ")"
+}
+
+func completionKind(v sabre.Value) int {
+	switch v.(type) {
+	case sabre.SpecialForm:
+		return completionKeyword
+
+	case sabre.MultiFn, *sabre.Fn:
+		return completionFunction
+	}
+
+	return completionVariable
+}
+
+func isSourceFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	ext := filepath.Ext(name)
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// uriToPath returns the file system path of a 'file' URI. Returns empty
+// string for other URIs.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+
+	path := u.Path
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		// windows paths are of the form '/C:/dir/file'.
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,261 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/lsp"
+)
+
+const mainSrc = `(def greet (fn* [name] (str "hi " name)))
+(greet   user.Name)
+(add1 "😀" (missing))
+`
+
+func TestServer_Serve(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "sabre-lsp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	util := "; Adds one to x.\n(def add1 (fn* [x] (inc x)))\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "util.lisp"), []byte(util), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	rootURI := "file://" + filepath.ToSlash(dir)
+	mainURI := rootURI + "/main.lisp"
+
+	scope := sabre.New()
+	_ = scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+	_ = scope.BindGo("user", &user{Name: "bob"})
+
+	in := frame(t,
+		request(1, "initialize", map[string]interface{}{"rootUri": rootURI}),
+		notification("initialized", map[string]interface{}{}),
+		notification("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri": mainURI, "languageId": "sabre", "version": 1, "text": mainSrc,
+			},
+		}),
+		request(2, "textDocument/hover", positionParams(mainURI, 1, 3)),
+		request(3, "textDocument/hover", positionParams(mainURI, 0, 25)),
+		request(4, "textDocument/definition", positionParams(mainURI, 2, 2)),
+		request(5, "textDocument/completion", positionParams(mainURI, 1, 15)),
+		request(6, "textDocument/completion", positionParams(mainURI, 0, 8)),
+		request(7, "textDocument/formatting", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": mainURI},
+		}),
+		request(8, "textDocument/rename", positionParams(mainURI, 0, 0)),
+		request(9, "shutdown", nil),
+		notification("exit", nil),
+	)
+
+	var out bytes.Buffer
+	srv := lsp.New(scope, lsp.WithDocs(map[string]string{"str": "Concatenates values."}))
+	if err := srv.Serve(context.Background(), in, &out); err != nil {
+		t.Fatalf("Serve() unexpected error: %v", err)
+	}
+
+	responses, notifications := readAll(t, &out)
+
+	diags := notifications["textDocument/publishDiagnostics"]
+	wantDiags := []interface{}{
+		map[string]interface{}{
+			"range":    rng(2, 12, 2, 19),
+			"severity": 1.0,
+			"code":     "unresolved-symbol",
+			"source":   "sabre",
+			"message":  "unable to resolve symbol 'missing'",
+		},
+	}
+	if len(diags) != 1 || !reflect.DeepEqual(diags[0]["diagnostics"], wantDiags) {
+		t.Errorf("publishDiagnostics got = %v, want = %v", diags, wantDiags)
+	}
+
+	caps := get(responses[1], "result", "capabilities")
+	for _, capability := range []string{"hoverProvider", "definitionProvider",
+		"completionProvider", "documentFormattingProvider"} {
+		if get(caps, capability) == nil {
+			t.Errorf("initialize: capability '%s' not advertised", capability)
+		}
+	}
+
+	if got := get(responses[2], "result", "contents", "value"); got != "```clojure\n(greet name)\n```" {
+		t.Errorf("hover(greet) got = %q", got)
+	}
+
+	if got, _ := get(responses[3], "result", "contents", "value").(string); !strings.HasSuffix(got, "\n\nConcatenates values.") {
+		t.Errorf("hover(str) got = %q", got)
+	}
+	if got := get(responses[3], "result", "range"); !reflect.DeepEqual(got, rng(0, 24, 0, 27)) {
+		t.Errorf("hover(str) range got = %v", got)
+	}
+
+	wantDef := map[string]interface{}{"uri": rootURI + "/util.lisp", "range": rng(1, 5, 1, 9)}
+	if got := get(responses[4], "result"); !reflect.DeepEqual(got, wantDef) {
+		t.Errorf("definition(add1) got = %v, want = %v", got, wantDef)
+	}
+
+	if got := labels(responses[5]); !reflect.DeepEqual(got, []string{"Name"}) {
+		t.Errorf("completion(user.N) got = %v", got)
+	}
+
+	if got := labels(responses[6]); !reflect.DeepEqual(got, []string{"greet"}) {
+		t.Errorf("completion(gre) got = %v", got)
+	}
+
+	wantEdits := []interface{}{
+		map[string]interface{}{
+			"range":   rng(0, 0, 3, 0),
+			"newText": strings.Replace(mainSrc, "   ", " ", 1),
+		},
+	}
+	if got := get(responses[7], "result"); !reflect.DeepEqual(got, wantEdits) {
+		t.Errorf("formatting got = %v, want = %v", got, wantEdits)
+	}
+
+	if got := get(responses[8], "error", "code"); got != -32601.0 {
+		t.Errorf("rename: expected method not found error, got = %v", responses[8])
+	}
+
+	if _, found := responses[9]; !found {
+		t.Errorf("shutdown: expected a response")
+	}
+}
+
+func TestServer_Serve_ExitWithoutShutdown(t *testing.T) {
+	t.Parallel()
+
+	in := frame(t, request(1, "hover", nil), notification("exit", nil))
+
+	var out bytes.Buffer
+	if err := lsp.New(nil).Serve(context.Background(), in, &out); err != lsp.ErrNoShutdown {
+		t.Errorf("Serve() expected ErrNoShutdown, got = %v", err)
+	}
+
+	responses, _ := readAll(t, &out)
+	if got := get(responses[1], "error", "code"); got != -32002.0 {
+		t.Errorf("expected server not initialized error, got = %v", responses[1])
+	}
+}
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func (u user) Greet() string { return "hi " + u.Name }
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	msg := notification(method, params)
+	msg["id"] = id
+	return msg
+}
+
+func notification(method string, params interface{}) map[string]interface{} {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		msg["params"] = params
+	}
+	return msg
+}
+
+func positionParams(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": char},
+	}
+}
+
+func rng(startLine, startChar, endLine, endChar float64) map[string]interface{} {
+	return map[string]interface{}{
+		"start": map[string]interface{}{"line": startLine, "character": startChar},
+		"end":   map[string]interface{}{"line": endLine, "character": endChar},
+	}
+}
+
+func frame(t *testing.T, msgs ...map[string]interface{}) io.Reader {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("failed to marshal message: %v", err)
+		}
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	return &buf
+}
+
+// readAll parses messages written by the server and returns responses by
+// their id and notification params by method.
+func readAll(t *testing.T, r io.Reader) (map[int]map[string]interface{}, map[string][]map[string]interface{}) {
+	responses := map[int]map[string]interface{}{}
+	notifications := map[string][]map[string]interface{}{}
+
+	rd := bufio.NewReader(r)
+	for {
+		header, err := textproto.NewReader(rd).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read header: %v", err)
+		}
+
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(rd, body); err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+
+		var msg map[string]interface{}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("invalid message '%s': %v", body, err)
+		}
+
+		if id, isResponse := msg["id"].(float64); isResponse {
+			responses[int(id)] = msg
+		} else {
+			method := msg["method"].(string)
+			params, _ := msg["params"].(map[string]interface{})
+			notifications[method] = append(notifications[method], params)
+		}
+	}
+
+	return responses, notifications
+}
+
+func get(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func labels(response map[string]interface{}) []string {
+	var labels []string
+	items, _ := response["result"].([]interface{})
+	for _, item := range items {
+		labels = append(labels, get(item, "label").(string))
+	}
+	return labels
+}
@@ -0,0 +1,287 @@
+// Package lsp implements a Language Server Protocol server for sabre source.
+// The server speaks JSON-RPC 2.0 framed with 'Content-Length' headers and
+// provides diagnostics using the lint package, hover documentation for
+// symbols bound in the root scope, go-to-definition for symbols defined using
+// (def ...) in opened or workspace files, completion of bound symbols and Go
+// struct members and document formatting using the format package.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/format"
+	"github.com/spy16/sabre/lint"
+)
+
+// ErrNoShutdown is returned by Serve when the client sends 'exit' without a
+// preceding 'shutdown' request.
+var ErrNoShutdown = errors.New("exit notification received before shutdown")
+
+// Option implementations can be provided to New() to configure the server.
+type Option func(s *Server)
+
+// WithDocs sets the documentation shown on hover for symbols bound in the
+// root scope.
+func WithDocs(docs map[string]string) Option {
+	return func(s *Server) {
+		for symbol, doc := range docs {
+			s.docs[symbol] = doc
+		}
+	}
+}
+
+// WithLintOptions sets the options used for creating the linter that
+// produces diagnostics.
+func WithLintOptions(opts ...lint.Option) Option {
+	return func(s *Server) {
+		s.lintOpts = append(s.lintOpts, opts...)
+	}
+}
+
+// WithFormatOptions sets the options used for formatting documents.
+func WithFormatOptions(opts ...format.Option) Option {
+	return func(s *Server) {
+		s.formatter = format.New(opts...)
+	}
+}
+
+// New returns a language server that analyzes documents against the given
+// root scope. If scope is nil, sabre.New() is used.
+func New(scope sabre.Scope, opts ...Option) *Server {
+	if scope == nil {
+		scope = sabre.New()
+	}
+
+	s := &Server{
+		scope:     scope,
+		docs:      map[string]string{},
+		formatter: format.New(),
+		documents: map[string]*document{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Server is a language server for sabre. A Server serves a single client
+// connection.
+type Server struct {
+	scope     sabre.Scope
+	docs      map[string]string
+	lintOpts  []lint.Option
+	formatter *format.Formatter
+
+	out         io.Writer
+	documents   map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends 'exit', r reaches EOF or ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.out = w
+
+	bodies := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		rd := bufio.NewReader(r)
+		for {
+			body, err := readMessage(rd)
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			select {
+			case bodies <- body:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-readErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+
+		case body := <-bodies:
+			exit, err := s.handle(body)
+			if err != nil || exit {
+				return err
+			}
+		}
+	}
+}
+
+// handle processes a single message. Returns true if the client requested
+// the server to exit.
+func (s *Server) handle(body []byte) (bool, error) {
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return false, s.reply(json.RawMessage("null"), nil,
+			&rpcError{Code: codeParseError, Message: err.Error()})
+	}
+
+	if msg.Method == "exit" {
+		if !s.shutdown {
+			return true, ErrNoShutdown
+		}
+		return true, nil
+	}
+
+	if !msg.isRequest() {
+		if !s.initialized || s.shutdown {
+			return false, nil
+		}
+		return false, s.notification(msg.Method, msg.Params)
+	}
+
+	var result interface{}
+	var err error
+	switch {
+	case !s.initialized && msg.Method != "initialize":
+		err = &rpcError{Code: codeServerNotInitialized, Message: "server is not initialized"}
+
+	case s.shutdown:
+		err = &rpcError{Code: codeInvalidRequest, Message: "server is shutting down"}
+
+	default:
+		result, err = s.request(msg.Method, msg.Params)
+	}
+
+	return false, s.reply(msg.ID, result, err)
+}
+
+func (s *Server) request(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "initialize":
+		var p initializeParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.initialize(p), nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/hover":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.hover(p), nil
+
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.definition(p), nil
+
+	case "textDocument/completion":
+		var p textDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.completion(p), nil
+
+	case "textDocument/formatting":
+		var p formattingParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.formatting(p)
+	}
+
+	return nil, &rpcError{
+		Code:    codeMethodNotFound,
+		Message: fmt.Sprintf("method '%s' is not supported", method),
+	}
+}
+
+// notification handles a notification from the client. Malformed and
+// unknown notifications are ignored since they cannot be responded to.
+func (s *Server) notification(method string, params json.RawMessage) error {
+	switch method {
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if unmarshalParams(params, &p) == nil {
+			return s.didOpen(p)
+		}
+
+	case "textDocument/didChange":
+		var p didChangeParams
+		if unmarshalParams(params, &p) == nil {
+			return s.didChange(p)
+		}
+
+	case "textDocument/didClose":
+		var p didCloseParams
+		if unmarshalParams(params, &p) == nil {
+			return s.didClose(p)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, err error) error {
+	msg := message{JSONRPC: "2.0", ID: id}
+
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeRequestFailed, Message: err.Error()}
+		}
+		msg.Error = rpcErr
+	} else {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = raw
+	}
+
+	return writeMessage(s.out, msg)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	return writeMessage(s.out, message{JSONRPC: "2.0", Method: method, Params: raw})
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package nrepl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// decodeValue reads one bencode value from the reader. Integers are decoded
+// as int64, byte strings as string, lists as []interface{} and dictionaries
+// as map[string]interface{}. Byte strings longer than maxSize are an error.
+func decodeValue(rd *bufio.Reader, maxSize int) (interface{}, error) {
+	b, err := rd.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b == 'i':
+		s, err := readUntil(rd, 'e')
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+
+	case b == 'l':
+		var list []interface{}
+		for {
+			if end, err := consumeEnd(rd); err != nil || end {
+				return list, err
+			}
+
+			v, err := decodeValue(rd, maxSize)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			list = append(list, v)
+		}
+
+	case b == 'd':
+		dict := map[string]interface{}{}
+		for {
+			if end, err := consumeEnd(rd); err != nil || end {
+				return dict, err
+			}
+
+			key, err := decodeValue(rd, maxSize)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+
+			k, isString := key.(string)
+			if !isString {
+				return nil, fmt.Errorf("dictionary key must be a string, not %T", key)
+			}
+
+			v, err := decodeValue(rd, maxSize)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			dict[k] = v
+		}
+
+	case b >= '0' && b <= '9':
+		s, err := readUntil(rd, ':')
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := strconv.Atoi(string(b) + s)
+		if err != nil || n > maxSize {
+			return nil, fmt.Errorf("string length %s%s exceeds the limit of %d bytes", string(b), s, maxSize)
+		}
+
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return string(buf), nil
+	}
+
+	return nil, fmt.Errorf("invalid bencode value starting with '%c'", b)
+}
+
+// encodeValue writes v in bencode format. Supported types are integers,
+// strings, bools (as 0/1), slices of supported types and maps with string
+// keys.
+func encodeValue(w *bufio.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case string:
+		_, err := fmt.Fprintf(w, "%d:%s", len(val), val)
+		return err
+
+	case int:
+		_, err := fmt.Fprintf(w, "i%de", val)
+		return err
+
+	case int64:
+		_, err := fmt.Fprintf(w, "i%de", val)
+		return err
+
+	case bool:
+		if val {
+			return encodeValue(w, 1)
+		}
+		return encodeValue(w, 0)
+
+	case []string:
+		list := make([]interface{}, len(val))
+		for i, s := range val {
+			list[i] = s
+		}
+		return encodeValue(w, list)
+
+	case []map[string]interface{}:
+		list := make([]interface{}, len(val))
+		for i, m := range val {
+			list[i] = m
+		}
+		return encodeValue(w, list)
+
+	case []interface{}:
+		w.WriteByte('l')
+		for _, item := range val {
+			if err := encodeValue(w, item); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte('e')
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		w.WriteByte('d')
+		for _, k := range keys {
+			if err := encodeValue(w, k); err != nil {
+				return err
+			}
+			if err := encodeValue(w, val[k]); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte('e')
+	}
+
+	return fmt.Errorf("cannot encode value of type %T", v)
+}
+
+func readUntil(rd *bufio.Reader, delim byte) (string, error) {
+	s, err := rd.ReadString(delim)
+	if err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return s[:len(s)-1], nil
+}
+
+// consumeEnd consumes the next byte if it is 'e' and returns true.
+func consumeEnd(rd *bufio.Reader) (bool, error) {
+	b, err := rd.Peek(1)
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+
+	if b[0] != 'e' {
+		return false, nil
+	}
+
+	_, err = rd.ReadByte()
+	return true, err
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
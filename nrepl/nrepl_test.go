@@ -0,0 +1,263 @@
+package nrepl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+func TestBencode(t *testing.T) {
+	t.Parallel()
+
+	v := map[string]interface{}{
+		"op":     "eval",
+		"id":     int64(42),
+		"status": []interface{}{"done", "error"},
+		"nested": map[string]interface{}{"unicode": "héllo"},
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := encodeValue(w, v); err != nil {
+		t.Fatalf("encodeValue() unexpected error: %v", err)
+	}
+	w.Flush()
+
+	want := "d2:idi42e6:nestedd7:unicode6:hélloe2:op4:eval6:statusl4:done5:erroree"
+	if buf.String() != want {
+		t.Errorf("encodeValue() got = %s, want = %s", buf.String(), want)
+	}
+
+	got, err := decodeValue(bufio.NewReader(&buf), DefaultMaxMessageSize)
+	if err != nil {
+		t.Fatalf("decodeValue() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("decodeValue() got = %#v, want = %#v", got, v)
+	}
+
+	if _, err := decodeValue(bufio.NewReader(strings.NewReader("d2:op")), DefaultMaxMessageSize); err == nil {
+		t.Errorf("decodeValue() expected error for truncated input")
+	}
+
+	for _, src := range []string{"999999999999999:x", "100000000000:", "d4:code11:(inc 1 2 3)e", "99999999999999999999:"} {
+		if _, err := decodeValue(bufio.NewReader(strings.NewReader(src)), 10); err == nil {
+			t.Errorf("decodeValue() expected error for string longer than the limit in %q", src)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	scope := sabre.New()
+	_ = scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- New(scope).Serve(ctx, l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	c := &testClient{t: t, conn: conn, rd: bufio.NewReader(conn)}
+
+	resp := c.roundTrip(map[string]interface{}{"op": "describe", "id": "1"})
+	if ops, _ := resp[0]["ops"].(map[string]interface{}); ops["eval"] == nil || ops["interrupt"] == nil {
+		t.Errorf("describe: unexpected ops %v", resp[0]["ops"])
+	}
+
+	resp = c.roundTrip(map[string]interface{}{"op": "clone", "id": "2"})
+	session, _ := resp[0]["new-session"].(string)
+	if session == "" {
+		t.Fatalf("clone: expected new-session, got %v", resp)
+	}
+
+	resp = c.roundTrip(map[string]interface{}{
+		"op": "eval", "id": "3", "session": session,
+		"code": "(def x 10) (inc x)",
+	})
+	if got := values(resp); !reflect.DeepEqual(got, []string{"x", "11"}) {
+		t.Errorf("eval: got values %v", got)
+	}
+
+	resp = c.roundTrip(map[string]interface{}{
+		"op": "load-file", "id": "4", "session": session,
+		"file": "(inc 1)\n(foo)", "file-path": "test.lisp",
+	})
+	if got := values(resp); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("load-file: got values %v", got)
+	}
+	if errMsg, _ := resp[1]["err"].(string); !strings.Contains(errMsg, "test.lisp") {
+		t.Errorf("load-file: expected error with file name, got %v", resp)
+	}
+	if !hasStatus(resp, "eval-error") {
+		t.Errorf("load-file: expected eval-error status, got %v", resp)
+	}
+
+	resp = c.roundTrip(map[string]interface{}{
+		"op": "completions", "id": "5", "session": session, "prefix": "macro",
+	})
+	want := []interface{}{
+		map[string]interface{}{"candidate": "macro*", "type": "special-form"},
+		map[string]interface{}{"candidate": "macroexpand", "type": "function"},
+	}
+	if !reflect.DeepEqual(resp[0]["completions"], want) {
+		t.Errorf("completions: got %v, want %v", resp[0]["completions"], want)
+	}
+
+	c.send(map[string]interface{}{
+		"op": "eval", "id": "6", "session": session,
+		"code": "((fn* [n] (recur (inc n))) 0)",
+	})
+	time.Sleep(50 * time.Millisecond)
+	c.send(map[string]interface{}{
+		"op": "interrupt", "id": "7", "session": session, "interrupt-id": "6",
+	})
+
+	statuses := map[string][]interface{}{}
+	for len(statuses) < 2 {
+		msg := c.receive()
+		id, _ := msg["id"].(string)
+		if status, ok := msg["status"].([]interface{}); ok {
+			statuses[id] = status
+		}
+	}
+	if !reflect.DeepEqual(statuses["6"], []interface{}{"interrupted", "done"}) {
+		t.Errorf("eval: expected interrupted status, got %v", statuses["6"])
+	}
+
+	resp = c.roundTrip(map[string]interface{}{"op": "foo", "id": "8"})
+	if !hasStatus(resp, "unknown-op") {
+		t.Errorf("expected unknown-op status, got %v", resp)
+	}
+
+	cancel()
+	if err := <-served; err != context.Canceled {
+		t.Errorf("Serve() expected context.Canceled, got %v", err)
+	}
+}
+
+func TestServer_MessageTooLarge(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = New(sabre.New(), WithMaxMessageSize(1024)).Serve(ctx, l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("d4:code999999999999999:x")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Errorf("expected the connection to be closed")
+	}
+
+	// the server keeps serving other clients.
+	conn2, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn2.Close()
+
+	c := &testClient{t: t, conn: conn2, rd: bufio.NewReader(conn2)}
+	resp := c.roundTrip(map[string]interface{}{"op": "eval", "code": "(do 1 2)", "id": "1"})
+	if resp[0]["value"] != "2" {
+		t.Errorf("eval: expected value 2, got %v", resp)
+	}
+}
+
+func TestServer_ListenAndServe(t *testing.T) {
+	t.Parallel()
+
+	err := New(sabre.New()).ListenAndServe(context.Background(), "10.1.2.3:7888")
+	if err == nil || !strings.Contains(err.Error(), "non-loopback") {
+		t.Errorf("ListenAndServe() expected non-loopback error, got %v", err)
+	}
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func (c *testClient) send(msg map[string]interface{}) {
+	w := bufio.NewWriter(c.conn)
+	if err := encodeValue(w, msg); err != nil {
+		c.t.Fatalf("failed to encode: %v", err)
+	}
+	w.Flush()
+}
+
+func (c *testClient) receive() map[string]interface{} {
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	v, err := decodeValue(c.rd, DefaultMaxMessageSize)
+	if err != nil {
+		c.t.Fatalf("failed to decode: %v", err)
+	}
+	return v.(map[string]interface{})
+}
+
+// roundTrip sends the request and returns responses until one with 'done'
+// status is received.
+func (c *testClient) roundTrip(req map[string]interface{}) []map[string]interface{} {
+	c.send(req)
+
+	var responses []map[string]interface{}
+	for {
+		resp := c.receive()
+		responses = append(responses, resp)
+		if hasStatus([]map[string]interface{}{resp}, "done") {
+			return responses
+		}
+	}
+}
+
+func values(responses []map[string]interface{}) []string {
+	var vals []string
+	for _, resp := range responses {
+		if v, found := resp["value"].(string); found {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+func hasStatus(responses []map[string]interface{}, status string) bool {
+	for _, resp := range responses {
+		list, _ := resp["status"].([]interface{})
+		for _, s := range list {
+			if s == status {
+				return true
+			}
+		}
+	}
+	return false
+}
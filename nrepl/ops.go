@@ -0,0 +1,276 @@
+package nrepl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/repl"
+)
+
+type opFunc func(s *Server, ctx context.Context, c *client, req map[string]interface{})
+
+var ops map[string]opFunc
+
+func init() {
+	ops = map[string]opFunc{
+		"describe":    (*Server).describe,
+		"clone":       (*Server).clone,
+		"close":       (*Server).close,
+		"ls-sessions": (*Server).lsSessions,
+		"eval":        (*Server).eval,
+		"load-file":   (*Server).loadFile,
+		"interrupt":   (*Server).interrupt,
+		"completions": (*Server).completions,
+	}
+}
+
+func (s *Server) handle(ctx context.Context, c *client, req map[string]interface{}) {
+	op, _ := req["op"].(string)
+
+	fn, found := ops[op]
+	if !found {
+		c.reply(req, map[string]interface{}{
+			"op":     op,
+			"status": []string{"error", "unknown-op", "done"},
+		})
+		return
+	}
+
+	fn(s, ctx, c, req)
+}
+
+func (s *Server) describe(_ context.Context, c *client, req map[string]interface{}) {
+	supported := map[string]interface{}{}
+	for op := range ops {
+		supported[op] = map[string]interface{}{}
+	}
+
+	c.reply(req, map[string]interface{}{
+		"ops": supported,
+		"versions": map[string]interface{}{
+			"nrepl": map[string]interface{}{
+				"major":          0,
+				"minor":          8,
+				"incremental":    0,
+				"version-string": "0.8.0",
+			},
+		},
+		"aux":    map[string]interface{}{"current-ns": s.currentNS()},
+		"status": []string{"done"},
+	})
+}
+
+func (s *Server) clone(_ context.Context, c *client, req map[string]interface{}) {
+	parent := s.scope
+	if id, found := req["session"].(string); found {
+		sess := s.session(id)
+		if sess == nil {
+			c.done(req, "error", "unknown-session")
+			return
+		}
+		parent = sess.scope
+	}
+
+	sess := s.newSession(parent)
+	c.reply(req, map[string]interface{}{
+		"new-session": sess.id,
+		"status":      []string{"done"},
+	})
+}
+
+func (s *Server) close(_ context.Context, c *client, req map[string]interface{}) {
+	id, _ := req["session"].(string)
+
+	s.mu.Lock()
+	sess, found := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	if !found {
+		c.done(req, "error", "unknown-session")
+		return
+	}
+
+	sess.close()
+	c.done(req, "session-closed")
+}
+
+func (s *Server) lsSessions(_ context.Context, c *client, req map[string]interface{}) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(ids)
+	c.reply(req, map[string]interface{}{
+		"sessions": ids,
+		"status":   []string{"done"},
+	})
+}
+
+func (s *Server) eval(ctx context.Context, c *client, req map[string]interface{}) {
+	code, isString := req["code"].(string)
+	if !isString {
+		c.done(req, "error", "no-code")
+		return
+	}
+
+	file, _ := req["file"].(string)
+	s.enqueue(ctx, c, req, code, file)
+}
+
+func (s *Server) loadFile(ctx context.Context, c *client, req map[string]interface{}) {
+	code, isString := req["file"].(string)
+	if !isString {
+		c.done(req, "error", "no-code")
+		return
+	}
+
+	file, _ := req["file-path"].(string)
+	if file == "" {
+		file, _ = req["file-name"].(string)
+	}
+	s.enqueue(ctx, c, req, code, file)
+}
+
+// enqueue schedules evaluation of the code in the session of the request.
+// Requests without a session are evaluated in a new session that is
+// discarded after evaluation.
+func (s *Server) enqueue(ctx context.Context, c *client, req map[string]interface{}, code, file string) {
+	var sess *session
+	if id, found := req["session"].(string); found {
+		if sess = s.session(id); sess == nil {
+			c.done(req, "error", "unknown-session")
+			return
+		}
+	} else {
+		sess = newSession(s.scope)
+	}
+
+	id, _ := req["id"].(string)
+	scheduled := sess.schedule(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		sess.setRunning(id, cancel)
+		defer sess.setRunning("", nil)
+
+		s.evalCode(ctx, c, sess, req, code, file)
+	})
+
+	if !scheduled {
+		c.done(req, "error", "session-closed")
+	}
+}
+
+// evalCode reads and evaluates forms in the code one by one and sends the
+// result of every form as a 'value' response.
+func (s *Server) evalCode(ctx context.Context, c *client, sess *session, req map[string]interface{},
+	code, file string) {
+	rd := s.factory.NewReader(strings.NewReader(code))
+	if file != "" {
+		rd.File = file
+	}
+
+	scope := sabre.WithContext(ctx, sess.scope)
+	for {
+		form, err := rd.One()
+		if err != nil {
+			if err == io.EOF {
+				c.done(req)
+			} else {
+				s.replyErr(c, req, err)
+			}
+			return
+		}
+
+		v, err := sabre.Eval(scope, form)
+		if err != nil {
+			err = rd.SourceMap().Annotate(err)
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				c.done(req, "interrupted")
+			} else {
+				s.replyErr(c, req, err)
+			}
+			return
+		}
+
+		c.reply(req, map[string]interface{}{
+			"value": s.printer(v),
+			"ns":    s.currentNS(),
+		})
+	}
+}
+
+func (s *Server) replyErr(c *client, req map[string]interface{}, err error) {
+	c.reply(req, map[string]interface{}{
+		"err": err.Error() + "\n",
+	})
+
+	root := err
+	for errors.Unwrap(root) != nil {
+		root = errors.Unwrap(root)
+	}
+
+	c.reply(req, map[string]interface{}{
+		"ex":      fmt.Sprintf("%T", err),
+		"root-ex": fmt.Sprintf("%T", root),
+		"status":  []string{"eval-error"},
+	})
+	c.done(req)
+}
+
+func (s *Server) interrupt(_ context.Context, c *client, req map[string]interface{}) {
+	id, _ := req["session"].(string)
+	sess := s.session(id)
+	if sess == nil {
+		c.done(req, "error", "unknown-session")
+		return
+	}
+
+	interruptID, _ := req["interrupt-id"].(string)
+	if !sess.interrupt(interruptID) {
+		c.done(req, "session-idle")
+		return
+	}
+
+	c.done(req)
+}
+
+func (s *Server) completions(_ context.Context, c *client, req map[string]interface{}) {
+	prefix, _ := req["prefix"].(string)
+
+	scope := s.scope
+	if id, found := req["session"].(string); found {
+		if sess := s.session(id); sess != nil {
+			scope = sess.scope
+		}
+	}
+
+	completions := []map[string]interface{}{}
+	for _, comp := range repl.Complete(scope, prefix) {
+		completions = append(completions, map[string]interface{}{
+			"candidate": comp.Candidate,
+			"type":      comp.Type,
+		})
+	}
+
+	c.reply(req, map[string]interface{}{
+		"completions": completions,
+		"status":      []string{"done"},
+	})
+}
+
+func (s *Server) currentNS() string {
+	if ns, ok := s.scope.(repl.NamespacedScope); ok {
+		return ns.CurrentNS()
+	}
+	return "user"
+}
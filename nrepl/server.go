@@ -0,0 +1,255 @@
+// Package nrepl implements an nREPL server for sabre. Editors supporting the
+// nREPL protocol (e.g., Emacs CIDER, Calva) can connect to the server to
+// evaluate code in the embedded sabre runtime of a running Go process.
+//
+// Messages are bencode dictionaries exchanged over TCP. The server supports
+// the 'describe', 'clone', 'close', 'ls-sessions', 'eval', 'load-file',
+// 'interrupt' and 'completions' operations.
+package nrepl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/repl"
+)
+
+// DefaultAddr is the address used by ListenAndServe() when addr is empty.
+const DefaultAddr = "127.0.0.1:7888"
+
+// DefaultMaxMessageSize is the maximum length of strings (e.g., the code to
+// evaluate) in client messages when not configured using WithMaxMessageSize().
+const DefaultMaxMessageSize = 16 << 20
+
+// Option implementations can be provided to New() to configure the server.
+type Option func(s *Server)
+
+// WithReaderFactory sets the factory used for creating readers for the code
+// sent by clients. Defaults to sabre.NewReader.
+func WithReaderFactory(factory repl.ReaderFactory) Option {
+	if factory == nil {
+		factory = repl.ReaderFactoryFunc(sabre.NewReader)
+	}
+
+	return func(s *Server) {
+		s.factory = factory
+	}
+}
+
+// WithPrinter sets the function used to render results of evaluation as
+// the 'value' of eval responses. Defaults to the String() of the value.
+func WithPrinter(f func(v sabre.Value) string) Option {
+	if f == nil {
+		f = func(v sabre.Value) string { return v.String() }
+	}
+
+	return func(s *Server) {
+		s.printer = f
+	}
+}
+
+// WithMaxMessageSize sets the maximum length in bytes of strings in client
+// messages. Connections sending longer strings are closed. Defaults to
+// DefaultMaxMessageSize.
+func WithMaxMessageSize(n int) Option {
+	if n <= 0 {
+		n = DefaultMaxMessageSize
+	}
+
+	return func(s *Server) {
+		s.maxMsgSize = n
+	}
+}
+
+// WithRemoteAccess allows clients to connect from non-loopback addresses.
+// Since clients can evaluate arbitrary code, this must only be used on
+// trusted networks.
+func WithRemoteAccess() Option {
+	return func(s *Server) {
+		s.allowRemote = true
+	}
+}
+
+// New returns an nREPL server that evaluates code in child scopes of the
+// given root scope. Sessions created by clients get their own child scope
+// while 'def' still binds in the root scope.
+func New(scope sabre.Scope, opts ...Option) *Server {
+	s := &Server{
+		scope:    scope,
+		sessions: map[string]*session{},
+	}
+
+	defaults := []Option{WithReaderFactory(nil), WithPrinter(nil), WithMaxMessageSize(0)}
+	for _, opt := range append(defaults, opts...) {
+		opt(s)
+	}
+
+	return s
+}
+
+// Server is an nREPL server.
+type Server struct {
+	scope       sabre.Scope
+	factory     repl.ReaderFactory
+	printer     func(v sabre.Value) string
+	allowRemote bool
+	maxMsgSize  int
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// ListenAndServe listens on the TCP address and serves clients until the
+// context is cancelled. If the host part of the address is empty, the
+// server listens on the loopback interface. Unless WithRemoteAccess() is
+// set, listening on other interfaces is an error.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	if host == "" {
+		host = "127.0.0.1"
+	} else if !s.allowRemote && !isLoopback(host) {
+		return fmt.Errorf("refusing to listen on non-loopback address '%s'", addr)
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ctx, l)
+}
+
+// Serve accepts connections on the listener and serves them until the
+// context is cancelled. Connections from non-loopback addresses are closed
+// immediately unless WithRemoteAccess() is set. The listener is closed when
+// Serve returns.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	if s.scope == nil {
+		return errors.New("scope is not set")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer s.closeSessions()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if !s.allowRemote && !isLoopback(conn.RemoteAddr().String()) {
+			conn.Close()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	defer func() {
+		// a bad message or a failing operation must not take down the host
+		// process. only the connection is dropped.
+		if v := recover(); v != nil {
+			conn.Close()
+		}
+	}()
+
+	c := &client{w: bufio.NewWriter(conn)}
+	rd := bufio.NewReader(conn)
+	for {
+		v, err := decodeValue(rd, s.maxMsgSize)
+		if err != nil {
+			return
+		}
+
+		msg, isDict := v.(map[string]interface{})
+		if !isDict {
+			continue
+		}
+
+		s.handle(ctx, c, msg)
+	}
+}
+
+// client serializes responses written to a connection.
+type client struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// reply sends a response to the request message. Request 'id' and
+// 'session' are included in the response.
+func (c *client) reply(req map[string]interface{}, resp map[string]interface{}) {
+	for _, key := range []string{"id", "session"} {
+		if v, found := req[key]; found {
+			if _, set := resp[key]; !set {
+				resp[key] = v
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if encodeValue(c.w, resp) == nil {
+		c.w.Flush()
+	}
+}
+
+func (c *client) done(req map[string]interface{}, status ...string) {
+	c.reply(req, map[string]interface{}{
+		"status": append(status, "done"),
+	})
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
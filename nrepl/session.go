@@ -0,0 +1,138 @@
+package nrepl
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+
+	"github.com/spy16/sabre"
+)
+
+// session represents an nREPL session. Evaluations in a session are run
+// one at a time in the order they are received.
+type session struct {
+	id    string
+	scope sabre.Scope
+
+	mu      sync.Mutex
+	pending []func()
+	running bool
+	stopped bool
+	evalID  string
+	cancel  context.CancelFunc
+}
+
+func newSession(parent sabre.Scope) *session {
+	return &session{
+		id:    newID(),
+		scope: sabre.NewScope(parent),
+	}
+}
+
+// schedule queues the task for execution. Returns false if the session is
+// stopped.
+func (sess *session) schedule(task func()) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.stopped {
+		return false
+	}
+
+	sess.pending = append(sess.pending, task)
+	if !sess.running {
+		sess.running = true
+		go sess.run()
+	}
+	return true
+}
+
+// run executes pending tasks until there are none left.
+func (sess *session) run() {
+	for {
+		sess.mu.Lock()
+		if len(sess.pending) == 0 {
+			sess.running = false
+			sess.mu.Unlock()
+			return
+		}
+		task := sess.pending[0]
+		sess.pending = sess.pending[1:]
+		sess.mu.Unlock()
+
+		task()
+	}
+}
+
+func (sess *session) setRunning(evalID string, cancel context.CancelFunc) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.evalID, sess.cancel = evalID, cancel
+}
+
+// interrupt cancels the running evaluation. If evalID is not empty, the
+// evaluation is cancelled only if it was started by the request with the
+// same id. Returns false if no evaluation was cancelled.
+func (sess *session) interrupt(evalID string) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.cancel == nil || (evalID != "" && evalID != sess.evalID) {
+		return false
+	}
+
+	sess.cancel()
+	return true
+}
+
+// stop stops accepting new tasks. Queued tasks are still executed.
+func (sess *session) stop() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.stopped = true
+}
+
+// close interrupts the running evaluation and stops the session.
+func (sess *session) close() {
+	sess.interrupt("")
+	sess.stop()
+}
+
+func (s *Server) newSession(parent sabre.Scope) *session {
+	sess := newSession(parent)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[sess.id] = sess
+	return sess
+}
+
+func (s *Server) session(id string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sessions[id]
+}
+
+func (s *Server) closeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, sess := range s.sessions {
+		sess.close()
+		delete(s.sessions, id)
+	}
+}
+
+// newID returns a random version 4 UUID.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
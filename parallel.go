@@ -0,0 +1,307 @@
+package sabre
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// PMap implements (pmap f coll+). f is invoked with items of the
+	// collections (one from each) in parallel using the pool of the scope
+	// (See PoolOf()). Returns the results as a list in the order of items.
+	// Stops at the end of the shortest collection.
+	PMap = ValueOf(pmap)
+
+	// PCalls implements (pcalls f*). Functions are invoked without arguments
+	// in parallel and the results are returned as a list in the order of
+	// functions.
+	PCalls = ValueOf(pcalls)
+
+	// PValues implements (pvalues expr*). Since the expressions must not be
+	// evaluated before the parallel evaluation starts, pvalues is a macro
+	// that wraps every expression in a function and invokes PCalls.
+	PValues = MultiFn{
+		Name:    "pvalues",
+		IsMacro: true,
+		Methods: []Fn{
+			{
+				Args:     []string{"exprs"},
+				Variadic: true,
+				Func:     expandPValues,
+			},
+		},
+	}
+
+	// ErrQuotaExceeded is returned when an evaluation runs more parallel
+	// tasks than its quota allows. See WithQuota().
+	ErrQuotaExceeded = errors.New("parallel task quota exceeded")
+)
+
+var defaultPool = NewPool(runtime.GOMAXPROCS(0))
+
+// WithPool returns a scope that delegates to the given scope and runs the
+// parallel calls (pmap, pcalls and pvalues) evaluated against it using the
+// pool.
+func WithPool(pool *Pool, scope Scope) Scope {
+	return &poolScope{Scope: scope, pool: pool}
+}
+
+// PoolOf returns the pool of the nearest scope in the parent chain that was
+// created using WithPool(). Returns a shared pool with GOMAXPROCS workers if
+// there is no such scope.
+func PoolOf(scope Scope) *Pool {
+	found := findScope(scope, func(s Scope) bool {
+		_, ok := s.(*poolScope)
+		return ok
+	})
+	if found != nil {
+		return found.(*poolScope).pool
+	}
+
+	return defaultPool
+}
+
+// WithQuota returns a scope that delegates to the given scope and allows the
+// evaluations against it to run at-most n parallel tasks in total (e.g., a
+// pmap over 10 items runs 10 tasks). Parallel forms that would exceed the
+// quota fail with ErrQuotaExceeded without running any task. Unlike the pool,
+// which limits how many tasks run at once, the quota limits the total work
+// and is typically created for every top-level evaluation.
+func WithQuota(n int, scope Scope) Scope {
+	remaining := int64(n)
+	return &quotaScope{Scope: scope, remaining: &remaining}
+}
+
+// NewPool returns a pool that runs at-most size tasks in parallel in addition
+// to the goroutines that submit the tasks. Pools can be shared by multiple
+// scopes to limit the parallelism of all their evaluations.
+func NewPool(size int) *Pool {
+	if size < 0 {
+		size = 0
+	}
+	return &Pool{slots: make(chan struct{}, size)}
+}
+
+// Pool is a bounded pool of workers for running tasks in parallel.
+type Pool struct {
+	slots chan struct{}
+}
+
+// Size returns the maximum number of tasks that run in parallel excluding
+// the goroutines running Run().
+func (pool *Pool) Size() int { return cap(pool.slots) }
+
+// Run invokes task for every index in [0, n) and returns the results in the
+// order of indices. Tasks are run by the calling goroutine and by as many
+// workers as the pool can spare, which ensures that nested calls (e.g., pmap
+// within pmap) make progress even when the pool is exhausted. The context
+// passed to the tasks is cancelled when a task fails or ctx is done, and the
+// first error is returned.
+func (pool *Pool) Run(ctx context.Context, n int, task func(ctx context.Context, i int) (Value, error)) ([]Value, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Value, n)
+	next, done := int64(-1), int64(0)
+
+	var once sync.Once
+	var firstErr error
+	work := func() {
+		for ctx.Err() == nil {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= n {
+				return
+			}
+
+			v, err := task(ctx, i)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = v
+			atomic.AddInt64(&done, 1)
+		}
+	}
+
+	var wg sync.WaitGroup
+spawn:
+	for w := 1; w < n; w++ {
+		select {
+		case pool.slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-pool.slots
+					wg.Done()
+				}()
+				work()
+			}()
+
+		default:
+			break spawn
+		}
+	}
+
+	work()
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if int(done) < n {
+		return nil, fmt.Errorf("evaluation cancelled: %w", ctx.Err())
+	}
+
+	return results, nil
+}
+
+type poolScope struct {
+	Scope
+	pool *Pool
+}
+
+func (ps *poolScope) unwrap() Scope { return ps.Scope }
+
+type quotaScope struct {
+	Scope
+	remaining *int64
+}
+
+func (qs *quotaScope) unwrap() Scope { return qs.Scope }
+
+// reserveQuota consumes n tasks from the quota of the nearest scope created
+// using WithQuota(). Nothing is consumed if the quota is not sufficient.
+func reserveQuota(scope Scope, n int) error {
+	found := findScope(scope, func(s Scope) bool {
+		_, ok := s.(*quotaScope)
+		return ok
+	})
+	if found == nil {
+		return nil
+	}
+
+	remaining := found.(*quotaScope).remaining
+	for {
+		left := atomic.LoadInt64(remaining)
+		if left < int64(n) {
+			return fmt.Errorf("%w: %d task(s) requested, %d left", ErrQuotaExceeded, n, left)
+		}
+
+		if atomic.CompareAndSwapInt64(remaining, left, left-int64(n)) {
+			return nil
+		}
+	}
+}
+
+func pmap(scope Scope, f Invokable, coll Value, colls ...Value) (Value, error) {
+	items, err := zipSeqs(append([]Value{coll}, colls...))
+	if err != nil {
+		return nil, err
+	}
+
+	return runParallel(scope, len(items), func(scope Scope, i int) (Value, error) {
+		return invokeEvaluated(scope, f, items[i])
+	})
+}
+
+func pcalls(scope Scope, fns ...Invokable) (Value, error) {
+	return runParallel(scope, len(fns), func(scope Scope, i int) (Value, error) {
+		return fns[i].Invoke(scope)
+	})
+}
+
+func expandPValues(_ Scope, args []Value) (Value, error) {
+	form := []Value{PCalls}
+	for _, expr := range args {
+		form = append(form, &Fn{Body: expr})
+	}
+
+	return &List{Values: form}, nil
+}
+
+// runParallel runs the tasks using the pool of the scope. Each task is
+// evaluated against a scope carrying the context of the task so that the
+// remaining tasks stop when one of them fails or the parent evaluation is
+// cancelled.
+func runParallel(scope Scope, n int, task func(scope Scope, i int) (Value, error)) (Value, error) {
+	if err := reserveQuota(scope, n); err != nil {
+		return nil, err
+	}
+
+	results, err := PoolOf(scope).Run(ContextOf(scope), n,
+		func(ctx context.Context, i int) (Value, error) {
+			return task(WithContext(ctx, scope), i)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{Values: results}, nil
+}
+
+// zipSeqs returns the argument lists formed by taking one item from each of
+// the sequences until one of them is exhausted.
+func zipSeqs(colls []Value) ([][]Value, error) {
+	seqs := make([]Seq, len(colls))
+	for i, coll := range colls {
+		seq, ok := coll.(Seq)
+		if !ok {
+			return nil, fmt.Errorf("value of type '%s' is not a sequence", reflect.TypeOf(coll))
+		}
+		seqs[i] = seq
+	}
+
+	var items [][]Value
+	for {
+		args := make([]Value, len(seqs))
+		for i, seq := range seqs {
+			if isEmptySeq(seq) {
+				return items, nil
+			}
+
+			args[i] = seq.First()
+			seqs[i] = seq.Next()
+		}
+		items = append(items, args)
+	}
+}
+
+// invokeEvaluated invokes f with the values that are already evaluated.
+// Functions with a body (e.g., created using fn*) bind the arguments as is
+// and receive the values directly. Other invokables evaluate the arguments
+// (e.g., functions created using ValueOf(), MultiFn) and receive the values
+// wrapped so that they are not evaluated again.
+func invokeEvaluated(scope Scope, f Invokable, vals []Value) (Value, error) {
+	if fn, isFn := f.(*Fn); isFn && fn.Func == nil {
+		return fn.Invoke(scope, vals...)
+	}
+
+	args := make([]Value, len(vals))
+	for i, v := range vals {
+		args[i] = evaluated{Value: v}
+	}
+	return f.Invoke(scope, args...)
+}
+
+// evaluated wraps a value that is already evaluated. Used for passing values
+// to Invoke() which evaluates the arguments.
+type evaluated struct {
+	Value
+}
+
+func (e evaluated) Eval(_ Scope) (Value, error) { return e.Value, nil }
+
+func bindParallel(scope Scope) {
+	_ = scope.Bind("pmap", PMap)
+	_ = scope.Bind("pcalls", PCalls)
+	_ = scope.Bind("pvalues", PValues)
+}
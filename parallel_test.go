@@ -0,0 +1,268 @@
+package sabre_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+func TestParallel(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr bool
+	}{
+		{
+			name: "PMap",
+			src:  `(pmap (fn* [a] (slow-inc a)) [1 2 3 4 5])`,
+			want: &sabre.List{Values: []sabre.Value{
+				sabre.Int64(2), sabre.Int64(3), sabre.Int64(4), sabre.Int64(5), sabre.Int64(6),
+			}},
+		},
+		{
+			name: "PMapMultipleColls",
+			src:  `(pmap + [1 2 3] '(10 20))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(11), sabre.Int64(22)}},
+		},
+		{
+			name: "PMapDoesNotReevaluate",
+			src:  `(pmap (fn* [v] v) ['a '(b)])`,
+			want: &sabre.List{Values: []sabre.Value{
+				sabre.Symbol{Value: "a"},
+				&sabre.List{Values: []sabre.Value{sabre.Symbol{Value: "b"}}},
+			}},
+		},
+		{
+			name: "PMapFnWithBody",
+			src:  `(pmap raw-fn [1 'a])`,
+			want: &sabre.List{Values: []sabre.Value{
+				sabre.String("sabre.Int64"), sabre.String("sabre.Symbol"),
+			}},
+		},
+		{
+			name: "PMapEmpty",
+			src:  `(pmap slow-inc [])`,
+			want: &sabre.List{},
+		},
+		{
+			name: "NestedPMap",
+			src:  `(pmap (fn* [a] (pmap slow-inc [a a])) [1 2 3])`,
+			want: &sabre.List{Values: []sabre.Value{
+				&sabre.List{Values: []sabre.Value{sabre.Int64(2), sabre.Int64(2)}},
+				&sabre.List{Values: []sabre.Value{sabre.Int64(3), sabre.Int64(3)}},
+				&sabre.List{Values: []sabre.Value{sabre.Int64(4), sabre.Int64(4)}},
+			}},
+		},
+		{
+			name:    "PMapNotInvokable",
+			src:     `(pmap 1 [1])`,
+			wantErr: true,
+		},
+		{
+			name:    "PMapNotSeq",
+			src:     `(pmap slow-inc 1)`,
+			wantErr: true,
+		},
+		{
+			name:    "PMapArgCount",
+			src:     `(pmap slow-inc)`,
+			wantErr: true,
+		},
+		{
+			name: "PMapAsValue",
+			src:  `(let* [f pmap] (f slow-inc [1 2]))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(2), sabre.Int64(3)}},
+		},
+		{
+			name: "PCallsAsArgument",
+			src:  `((fn* [call f] (call f f)) pcalls (fn* [] 1))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(1), sabre.Int64(1)}},
+		},
+		{
+			name:    "PCallsNotInvokable",
+			src:     `(pcalls (fn* [] 1) 2)`,
+			wantErr: true,
+		},
+		{
+			name: "PCalls",
+			src:  `(pcalls (fn* [] 1) (fn* [] (slow-inc 1)))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(1), sabre.Int64(2)}},
+		},
+		{
+			name: "PValues",
+			src:  `(let* [a 1] (pvalues (slow-inc a) a :x))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(2), sabre.Int64(1), sabre.Keyword("x")}},
+		},
+		{
+			name: "PValuesPCallsRebound",
+			src:  `(let* [pcalls 1] (pvalues pcalls 2))`,
+			want: &sabre.List{Values: []sabre.Value{sabre.Int64(1), sabre.Int64(2)}},
+		},
+		{
+			name: "PValuesEmpty",
+			src:  `(pvalues)`,
+			want: &sabre.List{},
+		},
+		{
+			name:    "PValuesError",
+			src:     `(pvalues 1 (undefined-fn))`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("+", func(a, b int) int { return a + b })
+			scope.BindGo("type-of", func(v sabre.Value) string { return fmt.Sprintf("%T", v) })
+			_ = scope.Bind("raw-fn", &sabre.Fn{
+				Args: []string{"v"},
+				Body: &sabre.List{Values: []sabre.Value{
+					sabre.Symbol{Value: "type-of"}, sabre.Symbol{Value: "v"},
+				}},
+			})
+			scope.BindGo("slow-inc", func(a int) int {
+				time.Sleep(time.Millisecond)
+				return a + 1
+			})
+
+			got, err := sabre.ReadEvalStr(scope, tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+
+			if !tt.wantErr && !sabre.Compare(got, tt.want) {
+				t.Errorf("Eval() want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParallel_Error(t *testing.T) {
+	t.Parallel()
+
+	var calls int64
+	scope := sabre.New()
+	scope.BindGo("check", func(a int) (int, error) {
+		atomic.AddInt64(&calls, 1)
+		if a == 2 {
+			return 0, errors.New("check failed")
+		}
+		time.Sleep(10 * time.Millisecond)
+		return a, nil
+	})
+
+	src := "(def items [1 2 3 4 5 6 7 8 9 10])\n(pmap check items)"
+	_, err := sabre.ReadEval(sabre.WithPool(sabre.NewPool(1), scope), strings.NewReader(src))
+
+	var ee sabre.EvalError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected EvalError, got %v", err)
+	}
+
+	if ee.Line != 2 || ee.Column != 1 {
+		t.Errorf("expected error at 2:1, got %d:%d", ee.Line, ee.Column)
+	}
+
+	if !strings.Contains(err.Error(), "check failed") {
+		t.Errorf("expected cause to be the first error, got %v", err)
+	}
+
+	if n := atomic.LoadInt64(&calls); n >= 10 {
+		t.Errorf("expected remaining items to be skipped after the error, got %d calls", n)
+	}
+}
+
+func TestParallel_Pool(t *testing.T) {
+	t.Parallel()
+
+	var running, peak int64
+	scope := sabre.New()
+	scope.BindGo("work", func(a int) int {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&running, -1)
+		return a
+	})
+
+	pool := sabre.NewPool(2)
+	if pool.Size() != 2 {
+		t.Errorf("Size() expected 2, got %d", pool.Size())
+	}
+
+	scoped := sabre.WithContext(context.Background(), sabre.WithPool(pool, scope))
+	if sabre.PoolOf(sabre.NewScope(scoped)) != pool {
+		t.Errorf("PoolOf() expected the pool of the wrapped scope")
+	}
+
+	if _, err := sabre.ReadEvalStr(scoped, "(pmap work [1 2 3 4 5 6 7 8])"); err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	// 2 workers of the pool and the evaluating goroutine.
+	if p := atomic.LoadInt64(&peak); p > 3 {
+		t.Errorf("expected at-most 3 parallel calls, got %d", p)
+	}
+}
+
+func TestParallel_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scope := sabre.WithContext(ctx, sabre.New())
+	_ = scope.Bind("block", sabre.ValueOf(func(scope sabre.Scope) error {
+		cancel()
+		<-sabre.ContextOf(scope).Done()
+		return nil
+	}))
+
+	_, err := sabre.ReadEvalStr(scope, "(pvalues (block) (block) (block))")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParallel_Quota(t *testing.T) {
+	t.Parallel()
+
+	var calls int64
+	scope := sabre.New()
+	scope.BindGo("inc", func(a int) int {
+		atomic.AddInt64(&calls, 1)
+		return a + 1
+	})
+	scoped := sabre.WithQuota(6, scope)
+
+	if _, err := sabre.ReadEvalStr(scoped, "(pmap inc [1 2 3])"); err != nil {
+		t.Fatalf("Eval() unexpected error: %v", err)
+	}
+
+	_, err := sabre.ReadEvalStr(scoped, "(pmap (fn* [a] (pvalues a a)) [1 2])")
+	if !errors.Is(err, sabre.ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	if n := atomic.LoadInt64(&calls); n != 3 {
+		t.Errorf("expected 3 calls, got %d", n)
+	}
+
+	// outer pmap consumed 2 tasks, failed pvalues calls consume nothing.
+	if _, err := sabre.ReadEvalStr(scoped, "(pcalls (fn* [] 1))"); err != nil {
+		t.Errorf("expected the remaining quota to allow 1 task, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package pprint
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// doc is the intermediate layout document built from values before being
+// rendered. Layout follows Wadler's "prettier printer" with an additional
+// align combinator for Lisp style argument alignment.
+type doc interface{}
+
+type (
+	// text is rendered as is and never contains new lines.
+	text string
+
+	// line is rendered as a single space if the enclosing group fits on the
+	// current line or as a new line followed by current indentation.
+	line struct{}
+
+	// hardline is always rendered as a new line.
+	hardline struct{}
+
+	// concat renders all the docs one after the other.
+	concat []doc
+
+	// nest increases the indentation of the wrapped doc relative to the
+	// current indentation level.
+	nest struct {
+		indent int
+		doc    doc
+	}
+
+	// align sets the indentation of the wrapped doc to the current column.
+	align struct{ doc doc }
+
+	// group renders the wrapped doc flat if it fits within the remaining
+	// width, breaking all its lines otherwise.
+	group struct{ doc doc }
+)
+
+type cmd struct {
+	indent int
+	flat   bool
+	doc    doc
+}
+
+func render(w io.Writer, width int, d doc) error {
+	var sb strings.Builder
+
+	col := 0
+	stack := []cmd{{doc: group{doc: d}}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := c.doc.(type) {
+		case nil:
+
+		case text:
+			sb.WriteString(string(d))
+			col += utf8.RuneCountInString(string(d))
+
+		case line:
+			if c.flat {
+				sb.WriteRune(' ')
+				col++
+			} else {
+				sb.WriteString("\n" + strings.Repeat(" ", c.indent))
+				col = c.indent
+			}
+
+		case hardline:
+			sb.WriteString("\n" + strings.Repeat(" ", c.indent))
+			col = c.indent
+
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, cmd{indent: c.indent, flat: c.flat, doc: d[i]})
+			}
+
+		case nest:
+			stack = append(stack, cmd{indent: c.indent + d.indent, flat: c.flat, doc: d.doc})
+
+		case align:
+			stack = append(stack, cmd{indent: col, flat: c.flat, doc: d.doc})
+
+		case group:
+			flat := c.flat || fits(width-col, cmd{flat: true, doc: d.doc}, stack)
+			stack = append(stack, cmd{indent: c.indent, flat: flat, doc: d.doc})
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// fits returns true if the given command rendered flat followed by the rest
+// of the stack up to the next line break fits within the remaining width.
+func fits(remaining int, next cmd, rest []cmd) bool {
+	pending := []cmd{next}
+	for remaining >= 0 {
+		if len(pending) == 0 {
+			if len(rest) == 0 {
+				return true
+			}
+			pending = append(pending, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+		}
+
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		switch d := c.doc.(type) {
+		case text:
+			remaining -= utf8.RuneCountInString(string(d))
+
+		case line:
+			if !c.flat {
+				return true
+			}
+			remaining--
+
+		case hardline:
+			return true
+
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				pending = append(pending, cmd{flat: c.flat, doc: d[i]})
+			}
+
+		case nest:
+			pending = append(pending, cmd{flat: c.flat, doc: d.doc})
+
+		case align:
+			pending = append(pending, cmd{flat: c.flat, doc: d.doc})
+
+		case group:
+			pending = append(pending, cmd{flat: c.flat, doc: d.doc})
+		}
+	}
+
+	return false
+}
+
+func join(docs []doc, sep doc) doc {
+	var res concat
+	for i, d := range docs {
+		if i > 0 {
+			res = append(res, sep)
+		}
+		res = append(res, d)
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+package pprint
+
+// Option implementations can be provided to New() to configure the pretty
+// printer.
+type Option func(p *Printer)
+
+// WithWidth sets the maximum line width the printer tries to fit values in.
+// Non-positive values reset the width to DefaultWidth.
+func WithWidth(width int) Option {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+
+	return func(p *Printer) {
+		p.width = width
+	}
+}
+
+// WithIndent sets the number of spaces used for indenting bodies of special
+// forms and hash-map values that do not fit on the same line as their key.
+func WithIndent(indent int) Option {
+	return func(p *Printer) {
+		p.indent = indent
+	}
+}
+
+// WithPrintLength limits the number of items printed for each collection.
+// Remaining items are replaced with "...". Zero disables the limit.
+func WithPrintLength(n int) Option {
+	return func(p *Printer) {
+		p.printLength = n
+	}
+}
+
+// WithPrintLevel limits the nesting depth of collections printed. Collections
+// nested deeper than the limit are printed as "#". Zero disables the limit.
+func WithPrintLevel(n int) Option {
+	return func(p *Printer) {
+		p.printLevel = n
+	}
+}
+
+// WithBodyForm registers a list head symbol (e.g., a macro name) to be laid
+// out in body style: 'headerArgs' arguments stay on the first line and the
+// rest are indented on following lines. Negative headerArgs removes the rule.
+func WithBodyForm(name string, headerArgs int) Option {
+	return func(p *Printer) {
+		if headerArgs < 0 {
+			delete(p.bodyForms, name)
+			return
+		}
+		p.bodyForms[name] = headerArgs
+	}
+}
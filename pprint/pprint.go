@@ -0,0 +1,222 @@
+// Package pprint provides a width-aware pretty printer for sabre values.
+// Collections that do not fit within the configured width are broken into
+// multiple lines with Lisp style indentation.
+package pprint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spy16/sabre"
+)
+
+// DefaultWidth is the line width used when no width is configured.
+const DefaultWidth = 80
+
+// New returns a new pretty printer configured with given options. Forms
+// returned by DefaultBodyForms() are laid out in body style by default.
+func New(opts ...Option) *Printer {
+	p := &Printer{
+		width:     DefaultWidth,
+		indent:    2,
+		bodyForms: DefaultBodyForms(),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Printer renders sabre values into human-readable text that fits within a
+// configured width.
+type Printer struct {
+	width       int
+	indent      int
+	printLength int
+	printLevel  int
+	bodyForms   map[string]int
+}
+
+// Fprint writes the pretty printed representation of the value to w.
+func (p *Printer) Fprint(w io.Writer, v sabre.Value) error {
+	return render(w, p.width, p.layout(v, 0))
+}
+
+// Sprint returns the pretty printed representation of the value.
+func (p *Printer) Sprint(v sabre.Value) string {
+	var sb strings.Builder
+	_ = p.Fprint(&sb, v)
+	return sb.String()
+}
+
+// Print pretty prints the value followed by a new line. Print has the same
+// signature as the printer expected by repl.WithPrinter() and can be used
+// directly as REPL printer.
+func (p *Printer) Print(w io.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case error:
+		_, err := fmt.Fprintf(w, "%+v\n", val)
+		return err
+
+	case sabre.Value:
+		if err := p.Fprint(w, val); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+
+	default:
+		_, err := fmt.Fprintf(w, "%v\n", val)
+		return err
+	}
+}
+
+// Bind binds 'pprint' and 'pprint-str' functions to the scope. 'pprint'
+// writes the pretty printed value to w, 'pprint-str' returns it as string.
+func (p *Printer) Bind(scope sabre.Scope, w io.Writer) error {
+	if err := scope.Bind("pprint", sabre.ValueOf(func(v sabre.Value) error {
+		return p.Print(w, v)
+	})); err != nil {
+		return err
+	}
+
+	return scope.Bind("pprint-str", sabre.ValueOf(func(v sabre.Value) sabre.String {
+		return sabre.String(p.Sprint(v))
+	}))
+}
+
+// Fprint writes the value to w using a printer with default configuration.
+func Fprint(w io.Writer, v sabre.Value) error { return New().Fprint(w, v) }
+
+// Sprint returns the value pretty printed using default configuration.
+func Sprint(v sabre.Value) string { return New().Sprint(v) }
+
+// DefaultBodyForms returns the list head symbols laid out in body style by
+// default (See WithBodyForm()) mapped to the number of header arguments. The
+// result is a new map on every call and can be modified by the caller.
+func DefaultBodyForms() map[string]int {
+	return map[string]int{
+		"fn*":    1,
+		"macro*": 1,
+		"let*":   1,
+		"if":     1,
+		"do":     0,
+		"def":    1,
+		"go":     0,
+		"future": 0,
+	}
+}
+
+func (p *Printer) layout(v sabre.Value, level int) doc {
+	switch val := v.(type) {
+	case *sabre.List:
+		return p.layoutList(val.Values, level)
+
+	case sabre.Values:
+		return p.layoutList(val, level)
+
+	case sabre.Vector:
+		return p.layoutSeq("[", "]", val.Values, level)
+
+	case sabre.Set:
+		return p.layoutSeq("#{", "}", val.Values, level)
+
+	case *sabre.HashMap:
+		return p.layoutMap(val, level)
+
+	case sabre.Module:
+		docs := make([]doc, len(val))
+		for i, form := range val {
+			docs[i] = p.layout(form, level)
+		}
+		return join(docs, hardline{})
+
+	default:
+		return text(sabre.PrStr(v))
+	}
+}
+
+func (p *Printer) layoutList(vals []sabre.Value, level int) doc {
+	if p.printLevel > 0 && level >= p.printLevel {
+		return text("#")
+	}
+
+	items := p.items(vals, level)
+	if len(items) < 2 {
+		return concat{text("("), concat(items), text(")")}
+	}
+
+	head, isSym := vals[0].(sabre.Symbol)
+	if !isSym {
+		return group{doc: concat{text("("), align{doc: join(items, line{})}, text(")")}}
+	}
+
+	if headerArgs, isBody := p.bodyForms[head.Value]; isBody {
+		if headerArgs > len(items)-1 {
+			headerArgs = len(items) - 1
+		}
+
+		header := join(items[:headerArgs+1], text(" "))
+		body := items[headerArgs+1:]
+		if len(body) == 0 {
+			return group{doc: concat{text("("), header, text(")")}}
+		}
+
+		return group{doc: concat{
+			text("("), header,
+			nest{indent: p.indent, doc: concat{line{}, join(body, line{})}},
+			text(")"),
+		}}
+	}
+
+	return group{doc: concat{
+		text("("), items[0], text(" "),
+		align{doc: join(items[1:], line{})},
+		text(")"),
+	}}
+}
+
+func (p *Printer) layoutSeq(begin, end string, vals []sabre.Value, level int) doc {
+	if p.printLevel > 0 && level >= p.printLevel {
+		return text("#")
+	}
+
+	items := p.items(vals, level)
+	return group{doc: concat{text(begin), align{doc: join(items, line{})}, text(end)}}
+}
+
+func (p *Printer) layoutMap(hm *sabre.HashMap, level int) doc {
+	if p.printLevel > 0 && level >= p.printLevel {
+		return text("#")
+	}
+
+	var entries []doc
+	for i, k := range sabre.SortedKeys(hm) {
+		if p.printLength > 0 && i >= p.printLength {
+			entries = append(entries, text("..."))
+			break
+		}
+
+		entries = append(entries, group{doc: concat{
+			p.layout(k, level+1), line{},
+			nest{indent: p.indent, doc: p.layout(hm.Data[k], level+1)},
+		}})
+	}
+
+	return group{doc: concat{text("{"), align{doc: join(entries, line{})}, text("}")}}
+}
+
+func (p *Printer) items(vals []sabre.Value, level int) []doc {
+	var docs []doc
+	for i, v := range vals {
+		if p.printLength > 0 && i >= p.printLength {
+			docs = append(docs, text("..."))
+			break
+		}
+		docs = append(docs, p.layout(v, level+1))
+	}
+	return docs
+}
@@ -0,0 +1,148 @@
+package pprint_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/pprint"
+)
+
+func TestPrinter_Sprint(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name string
+		src  string
+		opts []pprint.Option
+		want string
+	}{
+		{
+			name: "FitsOnLine",
+			src:  `[1 2 {:a "b"}]`,
+			want: `[1 2 {:a "b"}]`,
+		},
+		{
+			name: "BreakVector",
+			src:  `[100 200 300 400]`,
+			opts: []pprint.Option{pprint.WithWidth(10)},
+			want: "[100\n 200\n 300\n 400]",
+		},
+		{
+			name: "BreakHashMap",
+			src:  `{:name "Bob" :age 10 :tags [:a :b]}`,
+			opts: []pprint.Option{pprint.WithWidth(20)},
+			want: "{:age 10\n :name \"Bob\"\n :tags [:a :b]}",
+		},
+		{
+			name: "HashMapOrderSameAsPrStr",
+			src:  `{"b" 1 :b 2 \b 3 2 5 1.5 6}`,
+			want: `{"b" 1 1.5 6 2 5 :b 2 \b 3}`,
+		},
+		{
+			name: "FunctionCall",
+			src:  `(assert (= int-num 10) (= float-num 10.1234))`,
+			opts: []pprint.Option{pprint.WithWidth(30)},
+			want: "(assert (= int-num 10)\n        (= float-num 10.1234))",
+		},
+		{
+			name: "BodyForm",
+			src:  `(let* [a 1 b 2] (inc a) (inc b))`,
+			opts: []pprint.Option{pprint.WithWidth(20)},
+			want: "(let* [a 1 b 2]\n  (inc a)\n  (inc b))",
+		},
+		{
+			name: "CustomBodyForm",
+			src:  `(when-let [a 1] (inc a) (inc a))`,
+			opts: []pprint.Option{
+				pprint.WithWidth(20),
+				pprint.WithBodyForm("when-let", 1),
+			},
+			want: "(when-let [a 1]\n  (inc a)\n  (inc a))",
+		},
+		{
+			name: "PrintLength",
+			src:  `[1 2 3 4 5]`,
+			opts: []pprint.Option{pprint.WithPrintLength(3)},
+			want: `[1 2 3 ...]`,
+		},
+		{
+			name: "PrintLevel",
+			src:  `[1 [2 [3 [4]]]]`,
+			opts: []pprint.Option{pprint.WithPrintLevel(2)},
+			want: `[1 [2 #]]`,
+		},
+		{
+			name: "Module",
+			src:  "(def a 1)\n(def b 2)",
+			want: "(def a 1)\n(def b 2)",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := sabre.NewReader(strings.NewReader(tt.src)).All()
+			if err != nil {
+				t.Fatalf("All() unexpected error: %v", err)
+			}
+
+			if mod := form.(sabre.Module); len(mod) == 1 {
+				form = mod[0]
+			}
+
+			got := pprint.New(tt.opts...).Sprint(form)
+			if got != tt.want {
+				t.Errorf("Sprint() got = \n%s\nwant = \n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinter_Print(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p := pprint.New()
+
+	if err := p.Print(&buf, sabre.Vector{Values: sabre.Values{sabre.Int64(1)}}); err != nil {
+		t.Fatalf("Print() unexpected error: %v", err)
+	}
+
+	if err := p.Print(&buf, errors.New("failed")); err != nil {
+		t.Fatalf("Print() unexpected error: %v", err)
+	}
+
+	want := "[1]\nfailed\n"
+	if buf.String() != want {
+		t.Errorf("Print() got = %q, want = %q", buf.String(), want)
+	}
+}
+
+func TestPrinter_Bind(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	scope := sabre.New()
+	if err := pprint.New(pprint.WithWidth(5)).Bind(scope, &buf); err != nil {
+		t.Fatalf("Bind() unexpected error: %v", err)
+	}
+
+	got, err := sabre.ReadEvalStr(scope, `(pprint-str [1 2 3])`)
+	if err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	if want := sabre.String("[1\n 2\n 3]"); got != want {
+		t.Errorf("pprint-str got = %q, want = %q", got, want)
+	}
+
+	if _, err := sabre.ReadEvalStr(scope, `(pprint [1 2 3])`); err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	if want := "[1\n 2\n 3]\n"; buf.String() != want {
+		t.Errorf("pprint wrote = %q, want = %q", buf.String(), want)
+	}
+}
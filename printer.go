@@ -0,0 +1,222 @@
+package sabre
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var unescapeMap = map[rune]rune{
+	'"':  '"',
+	'\n': 'n',
+	'\\': '\\',
+	'\t': 't',
+	'\a': 'a',
+	'\f': 'f',
+	'\r': 'r',
+	'\b': 'b',
+	'\v': 'v',
+}
+
+// PrStr returns the readable representation of the given values separated by
+// a single space. Strings and characters are escaped, floats are printed with
+// the shortest representation that reads back to the same value and hash-map
+// entries are sorted by key. For all built-in types, reading the result using
+// Reader yields a value that is equivalent (see Compare()) to the original.
+func PrStr(vals ...Value) string {
+	return printValues(true, " ", vals)
+}
+
+// PrintStr returns the human-readable representation of the given values
+// separated by a single space. Unlike PrStr(), strings and characters are
+// not quoted or escaped.
+func PrintStr(vals ...Value) string {
+	return printValues(false, " ", vals)
+}
+
+// Str returns the concatenation of the human-readable representations of
+// the given values. Nil values are represented as an empty string.
+func Str(vals ...Value) string {
+	var sb strings.Builder
+	for _, v := range vals {
+		if v == nil || v == (Nil{}) {
+			continue
+		}
+
+		writeValue(&sb, v, false)
+	}
+	return sb.String()
+}
+
+func printValues(readably bool, sep string, vals []Value) string {
+	var sb strings.Builder
+	for i, v := range vals {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+
+		writeValue(&sb, v, readably)
+	}
+	return sb.String()
+}
+
+func writeValue(sb *strings.Builder, v Value, readably bool) {
+	switch val := v.(type) {
+	case nil:
+		sb.WriteString("nil")
+
+	case String:
+		if readably {
+			sb.WriteString(quoteString(string(val)))
+		} else {
+			sb.WriteString(string(val))
+		}
+
+	case Character:
+		if readably {
+			sb.WriteString(quoteChar(rune(val)))
+		} else {
+			sb.WriteRune(rune(val))
+		}
+
+	case Float64:
+		sb.WriteString(formatFloat(float64(val)))
+
+	case *List:
+		writeContainer(sb, val.Values, "(", ")", readably)
+
+	case Values:
+		writeContainer(sb, val, "(", ")", readably)
+
+	case Vector:
+		writeContainer(sb, val.Values, "[", "]", readably)
+
+	case Set:
+		writeContainer(sb, val.Values, "#{", "}", readably)
+
+	case Any:
+		if t, isTime := anyTime(val); isTime {
+			sb.WriteString("#inst " + quoteString(t.Format(time.RFC3339Nano)))
+		} else {
+			sb.WriteString(v.String())
+		}
+
+	case *HashMap:
+		var fields []Value
+		for _, k := range SortedKeys(val) {
+			fields = append(fields, k, val.Data[k])
+		}
+		writeContainer(sb, fields, "{", "}", readably)
+
+	default:
+		sb.WriteString(v.String())
+	}
+}
+
+func anyTime(any Any) (time.Time, bool) {
+	if !any.V.IsValid() || !any.V.CanInterface() {
+		return time.Time{}, false
+	}
+
+	t, isTime := any.V.Interface().(time.Time)
+	return t, isTime
+}
+
+func writeContainer(sb *strings.Builder, vals []Value, begin, end string, readably bool) {
+	sb.WriteString(begin)
+	for i, v := range vals {
+		if i > 0 {
+			sb.WriteRune(' ')
+		}
+		writeValue(sb, v, readably)
+	}
+	sb.WriteString(end)
+}
+
+// SortedKeys returns the keys of the hash-map ordered by their readable
+// representation (See PrStr()). Printers use this order so that printing
+// is deterministic.
+func SortedKeys(hm *HashMap) []Value {
+	keys := make([]Value, 0, len(hm.Data))
+	reprs := make(map[Value]string, len(hm.Data))
+	for k := range hm.Data {
+		keys = append(keys, k)
+		reprs[k] = PrStr(k)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return reprs[keys[i]] < reprs[keys[j]]
+	})
+	return keys
+}
+
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteRune('"')
+	for _, r := range s {
+		if esc, found := unescapeMap[r]; found {
+			sb.WriteRune('\\')
+			sb.WriteRune(esc)
+			continue
+		}
+
+		if !unicode.IsPrint(r) && r <= 0xFFFF {
+			sb.WriteString(`\u`)
+			sb.WriteString(hex4(r))
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+	sb.WriteRune('"')
+	return sb.String()
+}
+
+func quoteChar(r rune) string {
+	for name, lit := range charLiterals {
+		if lit == r {
+			return "\\" + name
+		}
+	}
+
+	if !unicode.IsPrint(r) || isSpace(r) {
+		return `\u` + hex4(r)
+	}
+
+	return "\\" + string(r)
+}
+
+func hex4(r rune) string {
+	s := strconv.FormatInt(int64(r), 16)
+	if len(s) < 4 {
+		s = strings.Repeat("0", 4-len(s)) + s
+	}
+	return strings.ToUpper(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "##Inf"
+
+	case math.IsInf(f, -1):
+		return "##-Inf"
+
+	case math.IsNaN(f):
+		return "##NaN"
+	}
+
+	abs := math.Abs(f)
+	if abs != 0 && (abs < 1e-3 || abs >= 1e7) {
+		return strconv.FormatFloat(f, 'e', -1, 64)
+	}
+
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsRune(s, '.') {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package sabre_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestPrStr_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	table := []sabre.Value{
+		sabre.Nil{},
+		sabre.Bool(true),
+		sabre.Int64(-42),
+		sabre.Float64(10),
+		sabre.Float64(3.1412),
+		sabre.Float64(1e-10),
+		sabre.Float64(-1.5e+20),
+		sabre.Float64(0.1 + 0.2),
+		sabre.Float64(math.Inf(1)),
+		sabre.Float64(math.Inf(-1)),
+		sabre.String("hello\tworld\n"),
+		sabre.String(`quote " and \ slash`),
+		sabre.String("bell\a and \x00 null"),
+		sabre.String("unicode λ 🧠"),
+		sabre.Character('a'),
+		sabre.Character('λ'),
+		sabre.Character(' '),
+		sabre.Character('\n'),
+		sabre.Character('\x00'),
+		sabre.Character('('),
+		sabre.Keyword("name"),
+		sabre.UUID{0xf8, 0x1d, 0x4f, 0xae},
+		sabre.TaggedLiteral{Tag: "foo/bar", Form: sabre.Vector{
+			Values: sabre.Values{sabre.String("x")},
+		}},
+		sabre.Symbol{Value: "foo.Bar"},
+		&sabre.List{Values: sabre.Values{
+			sabre.Symbol{Value: "inc"}, sabre.Int64(1),
+		}},
+		sabre.Vector{Values: sabre.Values{
+			sabre.String("a\"b"), sabre.Character(' '), sabre.Float64(2),
+		}},
+		sabre.Set{Values: sabre.Values{sabre.Int64(1), sabre.Keyword("a")}},
+		&sabre.HashMap{Data: map[sabre.Value]sabre.Value{
+			sabre.Keyword("name"):  sabre.String("Bob"),
+			sabre.Int64(10):        sabre.Float64(0.5),
+			sabre.Character('\t'):  sabre.Vector{},
+			sabre.String("nested"): &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}},
+		}},
+	}
+
+	for _, v := range table {
+		src := sabre.PrStr(v)
+		t.Run(src, func(t *testing.T) {
+			got, err := sabre.NewReader(strings.NewReader(src)).One()
+			if err != nil {
+				t.Fatalf("One() unexpected error: %v", err)
+			}
+
+			if !sabre.Compare(v, got) {
+				t.Errorf("round-trip mismatch: got = %#v, want = %#v", got, v)
+			}
+		})
+	}
+}
+
+func TestPrStr_NaN(t *testing.T) {
+	t.Parallel()
+
+	src := sabre.PrStr(sabre.Float64(math.NaN()))
+	if src != "##NaN" {
+		t.Fatalf("PrStr() expected ##NaN, got %s", src)
+	}
+
+	got, err := sabre.NewReader(strings.NewReader(src)).One()
+	if err != nil {
+		t.Fatalf("One() unexpected error: %v", err)
+	}
+
+	if f, isFloat := got.(sabre.Float64); !isFloat || !math.IsNaN(float64(f)) {
+		t.Errorf("expected NaN, got %#v", got)
+	}
+}
+
+func TestPrStr_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	hm := &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}}
+	for _, k := range []string{"e", "d", "c", "b", "a"} {
+		_ = hm.Set(sabre.Keyword(k), sabre.String(k))
+	}
+
+	want := `{:a "a" :b "b" :c "c" :d "d" :e "e"}`
+	for i := 0; i < 10; i++ {
+		if got := sabre.PrStr(hm); got != want {
+			t.Fatalf("PrStr() got = %s, want = %s", got, want)
+		}
+	}
+}
+
+func TestPrintStr(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		vals []sabre.Value
+		want string
+	}{
+		{
+			vals: []sabre.Value{sabre.String("hello"), sabre.Character('a')},
+			want: "hello a",
+		},
+		{
+			vals: []sabre.Value{sabre.Vector{Values: sabre.Values{sabre.String("x")}}},
+			want: "[x]",
+		},
+		{
+			vals: []sabre.Value{sabre.Nil{}, sabre.Float64(1)},
+			want: "nil 1.0",
+		},
+	}
+
+	for _, tt := range table {
+		if got := sabre.PrintStr(tt.vals...); got != tt.want {
+			t.Errorf("PrintStr() got = %s, want = %s", got, tt.want)
+		}
+	}
+}
+
+func TestStr(t *testing.T) {
+	t.Parallel()
+
+	scope := sabre.New()
+	got, err := sabre.ReadEvalStr(scope, `(str "a" nil \b 1 1.5 :c)`)
+	if err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	want := sabre.String("ab11.5:c")
+	if got != want {
+		t.Errorf("str got = %s, want = %s", got, want)
+	}
+
+	got, err = sabre.ReadEvalStr(scope, `(pr-str "a" \b)`)
+	if err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	want = sabre.String(`"a" \b`)
+	if got != want {
+		t.Errorf("pr-str got = %s, want = %s", got, want)
+	}
+}
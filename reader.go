@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const dispatchTrigger = '#'
@@ -34,7 +35,7 @@ var (
 		'\\': '\\',
 		't':  '\t',
 		'a':  '\a',
-		'f':  '\a',
+		'f':  '\f',
 		'r':  '\r',
 		'b':  '\b',
 		'v':  '\v',
@@ -66,6 +67,8 @@ func NewReader(rs io.Reader) *Reader {
 		rs:       bufio.NewReader(rs),
 		macros:   defaultReadTable(),
 		dispatch: defaultDispatchTable(),
+		tags:     defaultTagTable(),
+		spans:    newSourceMap(),
 	}
 }
 
@@ -78,13 +81,28 @@ type ReaderMacro func(rd *Reader, init rune) (Value, error)
 type Reader struct {
 	File string
 
-	rs          io.RuneReader
-	buf         []rune
-	line, col   int
-	lastCol     int
-	macros      map[rune]ReaderMacro
-	dispatch    map[rune]ReaderMacro
-	dispatching bool
+	// DefaultTag is invoked for tagged literals with no TagFunc registered.
+	// If nil, such literals are read as TaggedLiteral values.
+	DefaultTag func(tag string, form Value) (Value, error)
+
+	rs        io.RuneReader
+	buf       []rune
+	line, col int
+	lastCol   int
+	offset    int
+	spans     *SourceMap
+	lastSpan  Span
+	macros    map[rune]ReaderMacro
+	dispatch  map[rune]ReaderMacro
+	tags      map[string]TagFunc
+	inAnonFn  bool
+	depth     int
+	errDepth  int
+	recording bool
+	recorded  []rune
+	formStart int
+	nesting   int
+	keepSpans bool
 }
 
 // All consumes characters from stream until EOF and returns a list of all the
@@ -92,6 +110,11 @@ type Reader struct {
 // in the result.
 func (rd *Reader) All() (Value, error) {
 	var forms []Value
+	var spans []Span
+
+	rd.spans.reset()
+	rd.keepSpans = true
+	defer func() { rd.keepSpans = false }()
 
 	for {
 		form, err := rd.One()
@@ -104,16 +127,177 @@ func (rd *Reader) All() (Value, error) {
 		}
 
 		forms = append(forms, form)
+		spans = append(spans, rd.lastSpan)
 	}
 
+	rd.spans.recordElems(forms, spans)
 	return Module(forms), nil
 }
 
+// AllWithErrors is same as All() but does not stop at the first error. When
+// a form cannot be read, the error is recorded and the reader skips to the
+// end of the enclosing top-level form (or to the next line starting with '('
+// if the form is not terminated, in which case the error is reported at the
+// end of the line before it) and continues reading. Returns all forms
+// read successfully and all the errors encountered. This is useful for tools
+// (e.g., linters, formatters) that need to report every syntax error.
+func (rd *Reader) AllWithErrors() (Module, []ReadError) {
+	var forms []Value
+	var spans []Span
+	var errs []ReadError
+
+	rd.spans.reset()
+	rd.recording, rd.keepSpans = true, true
+	defer func() {
+		rd.recording, rd.keepSpans = false, false
+		rd.recorded = nil
+	}()
+
+	for {
+		rd.errDepth, rd.formStart = 0, 0
+		rd.recorded = rd.recorded[:0]
+		startLine, startCol := rd.line, rd.col
+
+		form, err := rd.One()
+		if err == nil {
+			forms = append(forms, form)
+			spans = append(spans, rd.lastSpan)
+			continue
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		readErr, ok := err.(ReadError)
+		if !ok {
+			readErr = ReadError{Cause: err, Position: rd.Position()}
+		}
+
+		for {
+			inner, isReadErr := readErr.Cause.(ReadError)
+			if !isReadErr {
+				break
+			}
+			readErr = inner
+		}
+		errs = append(errs, readErr)
+
+		if errors.Is(err, ErrEOF) {
+			// form is not terminated. restart reading from the first line
+			// within the form that starts with '(' if any. The error is
+			// reported where the form was cut so that the errors of the
+			// forms sharing the same EOF have distinct positions.
+			line, col, ok := rd.rewindToLine(startLine, startCol)
+			if !ok {
+				break
+			}
+			errs[len(errs)-1].Line, errs[len(errs)-1].Column = line+1, col
+			continue
+		}
+
+		if !rd.skipToTopLevel(rd.errDepth) {
+			break
+		}
+	}
+
+	rd.spans.recordElems(forms, spans)
+	return Module(forms), errs
+}
+
+// rewindToLine finds the first line starting with '(' in the runes recorded
+// since (line, col) after the start of the failed form, returns the runes
+// from that line back to the stream and returns the location of the end of
+// the line before it.
+func (rd *Reader) rewindToLine(line, col int) (int, int, bool) {
+	rec := rd.recorded
+	for i := rd.formStart + 1; i < len(rec); i++ {
+		if rec[i-1] != '\n' || rec[i] != '(' {
+			continue
+		}
+
+		var cutLine, cutCol int
+		for _, r := range rec[:i] {
+			if r == '\n' {
+				cutLine, cutCol = line, col
+				line++
+				col = 0
+			} else {
+				col++
+			}
+		}
+
+		rd.Unread(rec[i:]...)
+		rd.line, rd.col = line, col
+		return cutLine, cutCol, true
+	}
+
+	return 0, 0, false
+}
+
+// skipToTopLevel discards runes until 'depth' levels of containers are
+// closed or a line starting with '(' is found. Returns false if the stream
+// ended.
+func (rd *Reader) skipToTopLevel(depth int) bool {
+	for depth > 0 {
+		r, err := rd.NextRune()
+		if err != nil {
+			return false
+		}
+
+		switch r {
+		case '"':
+			if _, err := readString(rd, r); errors.Is(err, ErrEOF) {
+				return false
+			}
+
+		case ';':
+			if _, err := readComment(rd, r); err == io.EOF {
+				return false
+			}
+			rd.Unread('\n')
+
+		case '\\':
+			if _, err := rd.NextRune(); err != nil {
+				return false
+			}
+
+		case '(', '[', '{':
+			depth++
+
+		case ')', ']', '}':
+			depth--
+
+		case '\n':
+			next, err := rd.NextRune()
+			if err != nil {
+				return false
+			}
+
+			rd.Unread(next)
+			if next == '(' {
+				return true
+			}
+		}
+	}
+
+	return true
+}
+
 // One consumes characters from underlying stream until a complete form is
 // parsed and returns the form while ignoring the no-op forms like comments.
 // Except EOF, all errors will be wrapped with ReaderError type along with
 // the positional information obtained using Position().
 func (rd *Reader) One() (Value, error) {
+	if rd.nesting == 0 && !rd.keepSpans {
+		// spans of the forms read earlier are discarded so that the source
+		// map does not grow for the lifetime of the reader.
+		rd.spans.reset()
+	}
+
+	rd.nesting++
+	defer func() { rd.nesting-- }()
+
 	for {
 		form, err := rd.readOne()
 		if err != nil {
@@ -136,13 +320,6 @@ func (rd *Reader) IsTerminal(r rune) bool {
 		return true
 	}
 
-	if rd.dispatching {
-		_, found := rd.dispatch[r]
-		if found {
-			return true
-		}
-	}
-
 	_, found := rd.macros[r]
 	return found
 }
@@ -168,6 +345,17 @@ func (rd *Reader) SetMacro(init rune, macro ReaderMacro, isDispatch bool) {
 	}
 }
 
+// SetTag sets the given function as the handler for tagged literals of the
+// form '#tag form'. Overwrites if a handler is already present. If the fn is
+// nil, the tag is removed and will be handled by DefaultTag.
+func (rd *Reader) SetTag(tag string, fn TagFunc) {
+	if fn == nil {
+		delete(rd.tags, tag)
+		return
+	}
+	rd.tags[tag] = fn
+}
+
 // NextRune returns next rune from the stream and advances the stream.
 func (rd *Reader) NextRune() (rune, error) {
 	var r rune
@@ -190,6 +378,11 @@ func (rd *Reader) NextRune() (rune, error) {
 	} else {
 		rd.col++
 	}
+	rd.offset += utf8.RuneLen(r)
+
+	if rd.recording {
+		rd.recorded = append(rd.recorded, r)
+	}
 
 	return r, nil
 }
@@ -202,8 +395,8 @@ func (rd *Reader) Unread(runes ...rune) {
 	for _, r := range runes {
 		if r == '\n' {
 			newLine = true
-			break
 		}
+		rd.offset -= utf8.RuneLen(r)
 	}
 
 	if newLine {
@@ -213,6 +406,14 @@ func (rd *Reader) Unread(runes ...rune) {
 		rd.col--
 	}
 
+	if rd.recording {
+		n := len(rd.recorded) - len(runes)
+		if n < 0 {
+			n = 0
+		}
+		rd.recorded = rd.recorded[:n]
+	}
+
 	rd.buf = append(runes, rd.buf...)
 }
 
@@ -227,6 +428,10 @@ func (rd Reader) Position() Position {
 	}
 }
 
+// SourceMap returns the spans of the forms read by the last call to All(),
+// AllWithErrors() or One() on this reader. See SourceMap.
+func (rd *Reader) SourceMap() *SourceMap { return rd.spans }
+
 // SkipSpaces consumes and discards runes from stream repeatedly until a
 // character that is not a whitespace is identified. Along with standard
 // unicode  white-space characters "," is also considered  a white-space
@@ -247,12 +452,44 @@ func (rd *Reader) SkipSpaces() error {
 	return nil
 }
 
-// readOne is same as One() but always returns un-annotated errors.
+// Container reads forms until the 'end' rune is found and returns all the
+// forms read. No-op forms (e.g., comments) are discarded. This can be used
+// by reader macros to read custom container forms. formType is used only
+// in error messages.
+func (rd *Reader) Container(end rune, formType string) ([]Value, error) {
+	return readContainer(rd, -1, end, formType)
+}
+
+// readOne is same as One() but always returns un-annotated errors. Span of
+// the form read is recorded in the source map.
 func (rd *Reader) readOne() (Value, error) {
 	if err := rd.SkipSpaces(); err != nil {
 		return nil, err
 	}
 
+	if rd.depth == 0 {
+		// offset of the top-level form within the recorded runes. See
+		// AllWithErrors().
+		rd.formStart = len(rd.recorded)
+	}
+
+	start := rd.location()
+	form, err := rd.readForm()
+	if err != nil {
+		return nil, err
+	}
+
+	rd.lastSpan = Span{
+		File:  strings.TrimSpace(rd.File),
+		Start: start,
+		End:   rd.location(),
+	}
+	rd.spans.record(form, rd.lastSpan)
+
+	return form, nil
+}
+
+func (rd *Reader) readForm() (Value, error) {
 	r, err := rd.NextRune()
 	if err != nil {
 		return nil, err
@@ -298,25 +535,38 @@ func (rd *Reader) readOne() (Value, error) {
 	return v, nil
 }
 
+func (rd *Reader) location() Location {
+	return Location{
+		Offset: rd.offset,
+		Line:   rd.line + 1,
+		Column: rd.col + 1,
+	}
+}
+
 func (rd *Reader) execDispatch() (Value, error) {
 	pos := rd.Position()
 
 	r2, err := rd.NextRune()
 	if err != nil {
-		// ignore the error and let readOne handle it.
-		return nil, nil
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: while reading dispatch form", ErrEOF)
+		}
+		return nil, err
 	}
 
 	dispatchMacro, found := rd.dispatch[r2]
 	if !found {
 		rd.Unread(r2)
-		return nil, nil
-	}
+		if rd.IsTerminal(r2) {
+			return nil, fmt.Errorf("unexpected %q after '#'", r2)
+		}
 
-	rd.dispatching = true
-	defer func() {
-		rd.dispatching = false
-	}()
+		form, err := readTagged(rd)
+		if err != nil {
+			return nil, err
+		}
+		return setPosition(form, pos), nil
+	}
 
 	form, err := dispatchMacro(rd, r2)
 	if err != nil {
@@ -341,6 +591,9 @@ func (rd *Reader) annotateErr(e error) error {
 func readString(rd *Reader, _ rune) (Value, error) {
 	var b strings.Builder
 
+	// escape errors are reported only after consuming the entire string so
+	// that the reader is left at a sane position.
+	var escapeErr error
 	for {
 		r, err := rd.NextRune()
 		if err != nil {
@@ -361,13 +614,22 @@ func readString(rd *Reader, _ rune) (Value, error) {
 				return nil, err
 			}
 
-			// TODO: Support for Unicode escape \uNN format.
+			if r2 == 'u' {
+				r, err = readUnicodeEscape(rd)
+			} else {
+				r, err = getEscape(r2)
+			}
 
-			escaped, err := getEscape(r2)
 			if err != nil {
-				return nil, err
+				if errors.Is(err, ErrEOF) {
+					return nil, err
+				}
+
+				if escapeErr == nil {
+					escapeErr = err
+				}
+				continue
 			}
-			r = escaped
 		} else if r == '"' {
 			break
 		}
@@ -375,9 +637,36 @@ func readString(rd *Reader, _ rune) (Value, error) {
 		b.WriteRune(r)
 	}
 
+	if escapeErr != nil {
+		return nil, escapeErr
+	}
+
 	return String(b.String()), nil
 }
 
+// readUnicodeEscape reads the 4 hex-digits following a '\u' escape in a
+// string literal.
+func readUnicodeEscape(rd *Reader) (rune, error) {
+	var digits []rune
+	for len(digits) < 4 {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				return -1, fmt.Errorf("%w: while reading string", ErrEOF)
+			}
+			return -1, err
+		}
+		digits = append(digits, r)
+	}
+
+	num, err := strconv.ParseUint(string(digits), 16, 32)
+	if err != nil {
+		return -1, fmt.Errorf("illegal unicode escape '\\u%s'", string(digits))
+	}
+
+	return rune(num), nil
+}
+
 func readNumber(rd *Reader, init rune) (Value, error) {
 	numStr, err := readToken(rd, init)
 	if err != nil {
@@ -568,6 +857,209 @@ func readComment(rd *Reader, _ rune) (Value, error) {
 	return nil, ErrSkip
 }
 
+func readDiscard(rd *Reader, _ rune) (Value, error) {
+	if _, err := rd.One(); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: while reading discard form", ErrEOF)
+		}
+		return nil, err
+	}
+
+	return nil, ErrSkip
+}
+
+func readVarQuote(rd *Reader, _ rune) (Value, error) {
+	form, err := rd.One()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: while reading var quote", ErrEOF)
+		}
+		return nil, err
+	}
+
+	sym, isSymbol := form.(Symbol)
+	if !isSymbol {
+		return nil, fmt.Errorf("var quote expects a symbol, not '%s'", reflect.TypeOf(form))
+	}
+
+	return &List{
+		Values: []Value{
+			Symbol{Value: "var"},
+			sym,
+		},
+	}, nil
+}
+
+// readSymbolicValue reads the ##Inf, ##-Inf and ##NaN floating point values.
+func readSymbolicValue(rd *Reader, _ rune) (Value, error) {
+	token, err := readToken(rd, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case "Inf":
+		return Float64(math.Inf(1)), nil
+
+	case "-Inf":
+		return Float64(math.Inf(-1)), nil
+
+	case "NaN":
+		return Float64(math.NaN()), nil
+	}
+
+	return nil, fmt.Errorf("invalid symbolic value '##%s'", token)
+}
+
+func readRegex(rd *Reader, _ rune) (Value, error) {
+	var b strings.Builder
+
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: while reading regex", ErrEOF)
+			}
+			return nil, err
+		}
+
+		if r == '\\' {
+			// escape sequences are passed to the regex engine as is.
+			r2, err := rd.NextRune()
+			if err != nil {
+				if err == io.EOF {
+					return nil, fmt.Errorf("%w: while reading regex", ErrEOF)
+				}
+				return nil, err
+			}
+
+			b.WriteRune(r)
+			b.WriteRune(r2)
+			continue
+		} else if r == '"' {
+			break
+		}
+
+		b.WriteRune(r)
+	}
+
+	return CompileRegex(b.String())
+}
+
+func readAnonFn(rd *Reader, init rune) (Value, error) {
+	if rd.inAnonFn {
+		// '(' of the nested form is consumed already. count it so that
+		// AllWithErrors() skips the nested form along with the outer one.
+		if rd.errDepth < rd.depth+1 {
+			rd.errDepth = rd.depth + 1
+		}
+		return nil, errors.New("nested #()s are not allowed")
+	}
+
+	rd.inAnonFn = true
+	defer func() {
+		rd.inAnonFn = false
+	}()
+
+	body, err := readList(rd, init)
+	if err != nil {
+		return nil, err
+	}
+
+	maxArg, variadic := 0, false
+	body, err = rewriteAnonArgs(body, &maxArg, &variadic)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []Value
+	for i := 1; i <= maxArg; i++ {
+		args = append(args, Symbol{Value: fmt.Sprintf("%%%d", i)})
+	}
+
+	if variadic {
+		args = append(args, Symbol{Value: "&"}, Symbol{Value: "%&"})
+	}
+
+	return &List{
+		Values: []Value{
+			Symbol{Value: "fn*"},
+			Vector{Values: args},
+			body,
+		},
+	}, nil
+}
+
+// rewriteAnonArgs replaces '%' with '%1' in the anonymous function body and
+// records the highest positional argument used and use of '%&'.
+func rewriteAnonArgs(form Value, maxArg *int, variadic *bool) (Value, error) {
+	rewriteAll := func(vals []Value) ([]Value, error) {
+		res := make([]Value, len(vals))
+		for i, v := range vals {
+			rv, err := rewriteAnonArgs(v, maxArg, variadic)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = rv
+		}
+		return res, nil
+	}
+
+	switch f := form.(type) {
+	case Symbol:
+		if !strings.HasPrefix(f.Value, "%") {
+			return f, nil
+		}
+
+		switch arg := f.Value[1:]; arg {
+		case "":
+			f.Value = "%1"
+			if *maxArg < 1 {
+				*maxArg = 1
+			}
+
+		case "&":
+			*variadic = true
+
+		default:
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid anonymous function argument '%s'", f.Value)
+			}
+
+			if n > *maxArg {
+				*maxArg = n
+			}
+		}
+		return f, nil
+
+	case *List:
+		vals, err := rewriteAll(f.Values)
+		return &List{Values: vals, Position: f.Position}, err
+
+	case Vector:
+		vals, err := rewriteAll(f.Values)
+		return Vector{Values: vals, Position: f.Position}, err
+
+	case Set:
+		vals, err := rewriteAll(f.Values)
+		return Set{Values: vals, Position: f.Position}, err
+
+	case *HashMap:
+		hm := &HashMap{Position: f.Position, Data: map[Value]Value{}}
+		for k, v := range f.Data {
+			rv, err := rewriteAnonArgs(v, maxArg, variadic)
+			if err != nil {
+				return nil, err
+			}
+			hm.Data[k] = rv
+		}
+		return hm, nil
+	}
+
+	return form, nil
+}
+
 func quoteFormReader(expandFunc string) ReaderMacro {
 	return func(rd *Reader, _ rune) (Value, error) {
 		expr, err := rd.One()
@@ -620,17 +1112,20 @@ func parseScientific(numStr string) (Float64, error) {
 		return 0, fmt.Errorf("illegal scientific notation '%s'", numStr)
 	}
 
-	base, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
+	if _, err := strconv.ParseFloat(parts[0], 64); err != nil {
+		return 0, fmt.Errorf("illegal scientific notation '%s'", numStr)
+	}
+
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
 		return 0, fmt.Errorf("illegal scientific notation '%s'", numStr)
 	}
 
-	pow, err := strconv.ParseInt(parts[1], 10, 64)
+	v, err := strconv.ParseFloat(numStr, 64)
 	if err != nil {
 		return 0, fmt.Errorf("illegal scientific notation '%s'", numStr)
 	}
 
-	return Float64(base * math.Pow(10, float64(pow))), nil
+	return Float64(v), nil
 }
 
 func getEscape(r rune) (rune, error) {
@@ -672,8 +1167,17 @@ func readToken(rd *Reader, init rune) (string, error) {
 	return b.String(), nil
 }
 
-func readContainer(rd *Reader, _ rune, end rune, formType string) ([]Value, error) {
+func readContainer(rd *Reader, _ rune, end rune, formType string) (_ []Value, err error) {
 	var forms []Value
+	var spans []Span
+
+	rd.depth++
+	defer func() {
+		if err != nil && rd.errDepth < rd.depth {
+			rd.errDepth = rd.depth
+		}
+		rd.depth--
+	}()
 
 	for {
 		if err := rd.SkipSpaces(); err != nil {
@@ -704,8 +1208,10 @@ func readContainer(rd *Reader, _ rune, end rune, formType string) ([]Value, erro
 			return nil, err
 		}
 		forms = append(forms, expr)
+		spans = append(spans, rd.lastSpan)
 	}
 
+	rd.spans.recordElems(forms, spans)
 	return forms, nil
 }
 
@@ -729,8 +1235,13 @@ func defaultReadTable() map[rune]ReaderMacro {
 
 func defaultDispatchTable() map[rune]ReaderMacro {
 	return map[rune]ReaderMacro{
-		'{': readSet,
-		'}': unmatchedDelimiter,
+		'{':  readSet,
+		'}':  unmatchedDelimiter,
+		'_':  readDiscard,
+		'(':  readAnonFn,
+		'"':  readRegex,
+		'\'': readVarQuote,
+		'#':  readSymbolicValue,
 	}
 }
 
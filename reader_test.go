@@ -2,11 +2,15 @@ package sabre_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"math"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spy16/sabre"
 )
@@ -922,6 +926,675 @@ func TestReader_One_HashMap(t *testing.T) {
 	})
 }
 
+func TestReader_One_TaggedLiteral(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "UnknownTag",
+			src:  `#foo/bar 10`,
+			want: sabre.TaggedLiteral{Tag: "foo/bar", Form: sabre.Int64(10)},
+		},
+		{
+			name: "UUID",
+			src:  `#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`,
+			want: sabre.UUID{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0,
+				0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6},
+		},
+		{
+			name:    "InvalidUUID",
+			src:     `#uuid "f81d4fae"`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidInst",
+			src:     `#inst 10`,
+			wantErr: true,
+		},
+		{
+			name:    "MissingForm",
+			src:     `#foo`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_Discard(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "TopLevel",
+			src:  `#_(foo bar) :hello`,
+			want: sabre.Keyword("hello"),
+		},
+		{
+			name: "WithinContainer",
+			src:  `[1 #_ 2 #_#_ 3 4 5]`,
+			want: sabre.Vector{
+				Values: sabre.Values{sabre.Int64(1), sabre.Int64(5)},
+				Position: sabre.Position{
+					File:   "<string>",
+					Line:   1,
+					Column: 1,
+				},
+			},
+		},
+		{
+			name: "SymbolWithUnderscore",
+			src:  `#_foo_bar baz_qux`,
+			want: sabre.Symbol{
+				Value: "baz_qux",
+				Position: sabre.Position{
+					File:   "<string>",
+					Line:   1,
+					Column: 11,
+				},
+			},
+		},
+		{
+			name:    "EOF",
+			src:     `#_`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_AnonFn(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "SingleArg",
+			src:  `#(inc %)`,
+			want: &sabre.List{
+				Values: sabre.Values{
+					sabre.Symbol{Value: "fn*"},
+					sabre.Vector{Values: sabre.Values{sabre.Symbol{Value: "%1"}}},
+					&sabre.List{
+						Values: sabre.Values{
+							sabre.Symbol{
+								Value: "inc",
+								Position: sabre.Position{
+									File:   "<string>",
+									Line:   1,
+									Column: 3,
+								},
+							},
+							sabre.Symbol{
+								Value: "%1",
+								Position: sabre.Position{
+									File:   "<string>",
+									Line:   1,
+									Column: 7,
+								},
+							},
+						},
+						Position: sabre.Position{
+							File:   "<string>",
+							Line:   1,
+							Column: 2,
+						},
+					},
+				},
+				Position: sabre.Position{
+					File:   "<string>",
+					Line:   1,
+					Column: 1,
+				},
+			},
+		},
+		{
+			name: "PositionalAndRest",
+			src:  `#(f %2 %&)`,
+			want: &sabre.List{
+				Values: sabre.Values{
+					sabre.Symbol{Value: "fn*"},
+					sabre.Vector{Values: sabre.Values{
+						sabre.Symbol{Value: "%1"},
+						sabre.Symbol{Value: "%2"},
+						sabre.Symbol{Value: "&"},
+						sabre.Symbol{Value: "%&"},
+					}},
+					&sabre.List{
+						Values: sabre.Values{
+							sabre.Symbol{
+								Value: "f",
+								Position: sabre.Position{
+									File:   "<string>",
+									Line:   1,
+									Column: 3,
+								},
+							},
+							sabre.Symbol{
+								Value: "%2",
+								Position: sabre.Position{
+									File:   "<string>",
+									Line:   1,
+									Column: 5,
+								},
+							},
+							sabre.Symbol{
+								Value: "%&",
+								Position: sabre.Position{
+									File:   "<string>",
+									Line:   1,
+									Column: 8,
+								},
+							},
+						},
+						Position: sabre.Position{
+							File:   "<string>",
+							Line:   1,
+							Column: 2,
+						},
+					},
+				},
+				Position: sabre.Position{
+					File:   "<string>",
+					Line:   1,
+					Column: 1,
+				},
+			},
+		},
+		{
+			name:    "Nested",
+			src:     `#(f #(g %))`,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidArg",
+			src:     `#(f %0)`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_Regex(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "Simple",
+			src:  `#"[a-z]+\d"`,
+			want: sabre.Regex{Regexp: regexp.MustCompile(`[a-z]+\d`)},
+		},
+		{
+			name: "EscapedQuote",
+			src:  `#"say \"hi\""`,
+			want: sabre.Regex{Regexp: regexp.MustCompile(`say \"hi\"`)},
+		},
+		{
+			name:    "Invalid",
+			src:     `#"[a-z"`,
+			wantErr: true,
+		},
+		{
+			name:    "EOF",
+			src:     `#"abc`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_VarQuote(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "Symbol",
+			src:  `#'foo`,
+			want: &sabre.List{
+				Values: sabre.Values{
+					sabre.Symbol{Value: "var"},
+					sabre.Symbol{
+						Value: "foo",
+						Position: sabre.Position{
+							File:   "<string>",
+							Line:   1,
+							Column: 3,
+						},
+					},
+				},
+				Position: sabre.Position{
+					File:   "<string>",
+					Line:   1,
+					Column: 1,
+				},
+			},
+		},
+		{
+			name:    "NotSymbol",
+			src:     `#'10`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_SymbolicValue(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name: "Inf",
+			src:  `##Inf`,
+			want: sabre.Float64(math.Inf(1)),
+		},
+		{
+			name: "NegativeInf",
+			src:  `##-Inf`,
+			want: sabre.Float64(math.Inf(-1)),
+		},
+		{
+			name:    "Invalid",
+			src:     `##Foo`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_One_BareDispatch(t *testing.T) {
+	executeReaderTests(t, []readerTestCase{
+		{
+			name:    "FollowedBySpace",
+			src:     `# 1`,
+			wantErr: true,
+		},
+		{
+			name:    "FollowedByDelimiter",
+			src:     `(#)`,
+			wantErr: true,
+		},
+		{
+			name:    "AtEOF",
+			src:     `#`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestReader_SetTag(t *testing.T) {
+	t.Parallel()
+
+	scope := sabre.New()
+	double, err := sabre.ReadEvalStr(scope, `(fn* [x] [x x])`)
+	if err != nil {
+		t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+	}
+
+	rd := sabre.NewReader(strings.NewReader(
+		`#inst "2020-01-01T10:00:00Z" #go/inc 1 #lisp/double (a b) #unknown :x`,
+	))
+	rd.SetTag("go/inc", func(form sabre.Value) (sabre.Value, error) {
+		return form.(sabre.Int64) + 1, nil
+	})
+	rd.SetTag("lisp/double", sabre.TagFuncOf(scope, double.(sabre.Invokable)))
+	rd.DefaultTag = func(tag string, form sabre.Value) (sabre.Value, error) {
+		return sabre.String(tag), nil
+	}
+
+	got, err := rd.All()
+	if err != nil {
+		t.Fatalf("All() unexpected error: %v", err)
+	}
+
+	list := &sabre.List{Values: sabre.Values{
+		sabre.Symbol{Value: "a"}, sabre.Symbol{Value: "b"},
+	}}
+	want := sabre.Module{
+		sabre.ValueOf(time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)),
+		sabre.Int64(2),
+		sabre.Vector{Values: sabre.Values{list, list}},
+		sabre.String("unknown"),
+	}
+	if !sabre.Compare(got, want) {
+		t.Errorf("All() got = %v, want = %v", got, want)
+	}
+
+	if got := sabre.PrStr(want[0]); got != `#inst "2020-01-01T10:00:00Z"` {
+		t.Errorf("PrStr() got = %s", got)
+	}
+
+	tagged := sabre.TaggedLiteral{Tag: "foo/bar", Form: sabre.Vector{
+		Values: sabre.Values{sabre.String("x")},
+	}}
+	if got := sabre.PrStr(tagged); got != `#foo/bar ["x"]` {
+		t.Errorf("PrStr() got = %s", got)
+	}
+}
+
+func TestReader_AllWithErrors(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name      string
+		src       string
+		wantForms []string
+		wantErrs  []sabre.Position
+	}{
+		{
+			name:      "NoErrors",
+			src:       `(def a 1) [1 2]`,
+			wantForms: []string{"(def a 1)", "[1 2]"},
+		},
+		{
+			name: "ErrorsInMultipleForms",
+			src: `(def a 1x2 "ignored)")
+(def b [1 "\q" ; comment )
+        2])
+)
+(def c #{1 1})
+(def d {:a})`,
+			wantForms: []string{},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 10},
+				{File: "<string>", Line: 2, Column: 14},
+				{File: "<string>", Line: 4, Column: 1},
+				{File: "<string>", Line: 5, Column: 13},
+				{File: "<string>", Line: 6, Column: 11},
+			},
+		},
+		{
+			name: "ValidFormsBetweenErrors",
+			src: `(def a 1)
+(def b (foo 1x2 (bar)) 10)
+(def c 3)`,
+			wantForms: []string{"(def a 1)", "(def c 3)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 2, Column: 15},
+			},
+		},
+		{
+			name: "UnterminatedForm",
+			src: `(def a (foo
+(def b 2)`,
+			wantForms: []string{"(def b 2)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 11},
+			},
+		},
+		{
+			name: "UnterminatedPositions",
+			src: `(def a "x
+  (foo)"
+ (bar
+(def b
+(def c 3)`,
+			wantForms: []string{"(def c 3)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 3, Column: 5},
+				{File: "<string>", Line: 4, Column: 6},
+			},
+		},
+		{
+			name:      "NestedAnonFn",
+			src:       `(def f #(+ % #(inc %))) (def b 2)`,
+			wantForms: []string{"(def b 2)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 15},
+			},
+		},
+		{
+			name:      "UnterminatedAfterForm",
+			src:       "(def x 1)\n(",
+			wantForms: []string{"(def x 1)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:      "UnmatchedThenUnterminated",
+			src:       "(a))\n(b c",
+			wantForms: []string{"(a)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 4},
+				{File: "<string>", Line: 2, Column: 4},
+			},
+		},
+		{
+			name:      "UnterminatedLines",
+			src:       "(a\n(b",
+			wantForms: []string{},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 2},
+				{File: "<string>", Line: 2, Column: 2},
+			},
+		},
+		{
+			name:      "CommentBeforeUnterminated",
+			src:       "; note\n(a",
+			wantForms: []string{},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 2, Column: 2},
+			},
+		},
+		{
+			name:      "PrematureEOF",
+			src:       `(def a 1) (def b`,
+			wantForms: []string{"(def a 1)"},
+			wantErrs: []sabre.Position{
+				{File: "<string>", Line: 1, Column: 16},
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, errs := sabre.NewReader(strings.NewReader(tt.src)).AllWithErrors()
+
+			gotForms := []string{}
+			for _, f := range forms {
+				gotForms = append(gotForms, f.String())
+			}
+			if !reflect.DeepEqual(gotForms, tt.wantForms) {
+				t.Errorf("AllWithErrors() forms = %v, want = %v", gotForms, tt.wantForms)
+			}
+
+			var gotErrs []sabre.Position
+			for _, e := range errs {
+				gotErrs = append(gotErrs, e.Position)
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("AllWithErrors() errors = %v, want = %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReader_SourceMap(t *testing.T) {
+	t.Parallel()
+
+	rd := sabre.NewReader(strings.NewReader("(def x \"h\u00e9llo\")\n[x 42 42]"))
+	mod, err := rd.All()
+	if err != nil {
+		t.Fatalf("All() unexpected error: %v", err)
+	}
+	sm := rd.SourceMap()
+
+	span := func(startOff, startLine, startCol, endOff, endLine, endCol int) sabre.Span {
+		return sabre.Span{
+			File:  "<string>",
+			Start: sabre.Location{Offset: startOff, Line: startLine, Column: startCol},
+			End:   sabre.Location{Offset: endOff, Line: endLine, Column: endCol},
+		}
+	}
+
+	forms := mod.(sabre.Module)
+	list := forms[0].(*sabre.List)
+	vec := forms[1].(sabre.Vector)
+
+	table := []struct {
+		name      string
+		form      sabre.Value
+		want      sabre.Span
+		wantFound bool
+	}{
+		{
+			name:      "List",
+			form:      list,
+			want:      span(0, 1, 1, 16, 1, 16),
+			wantFound: true,
+		},
+		{
+			name:      "Symbol",
+			form:      list.Values[1],
+			want:      span(5, 1, 6, 6, 1, 7),
+			wantFound: true,
+		},
+		{
+			name:      "Vector",
+			form:      vec,
+			want:      span(17, 2, 1, 26, 2, 10),
+			wantFound: true,
+		},
+		{
+			name:      "Atom",
+			form:      sabre.String("h\u00e9llo"),
+			wantFound: false,
+		},
+		{
+			name:      "NotRead",
+			form:      &sabre.List{},
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := sm.Span(tt.form)
+			if found != tt.wantFound {
+				t.Fatalf("Span() found = %t, want = %t", found, tt.wantFound)
+			}
+			if got != tt.want {
+				t.Errorf("Span() got = %s, want = %s", got, tt.want)
+			}
+		})
+	}
+
+	got, found := sm.ElementSpan(list, 2)
+	if want := span(7, 1, 8, 15, 1, 15); !found || got != want {
+		t.Errorf("ElementSpan() got = %s (found=%t), want = %s", got, found, want)
+	}
+
+	got, found = sm.ElementSpan(vec, 1)
+	if want := span(20, 2, 4, 22, 2, 6); !found || got != want {
+		t.Errorf("ElementSpan() got = %s (found=%t), want = %s", got, found, want)
+	}
+
+	got, found = sm.ElementSpan(vec, 2)
+	if want := span(23, 2, 7, 25, 2, 9); !found || got != want {
+		t.Errorf("ElementSpan() got = %s (found=%t), want = %s", got, found, want)
+	}
+
+	got, found = sm.ElementSpan(mod, 1)
+	if want := span(17, 2, 1, 26, 2, 10); !found || got != want {
+		t.Errorf("ElementSpan() got = %s (found=%t), want = %s", got, found, want)
+	}
+}
+
+func TestReader_SourceMap_One(t *testing.T) {
+	t.Parallel()
+
+	rd := sabre.NewReader(strings.NewReader("[1] [2]"))
+	first, err := rd.One()
+	if err != nil {
+		t.Fatalf("One() unexpected error: %v", err)
+	}
+
+	if _, found := rd.SourceMap().Span(first); !found {
+		t.Errorf("Span() expected span of the form read")
+	}
+
+	if _, err := rd.One(); err != nil {
+		t.Fatalf("One() unexpected error: %v", err)
+	}
+
+	if _, found := rd.SourceMap().Span(first); found {
+		t.Errorf("Span() expected spans of earlier forms to be discarded")
+	}
+}
+
+func TestSourceMap_Annotate(t *testing.T) {
+	t.Parallel()
+
+	rd := sabre.NewReader(strings.NewReader("[#fail 1\n #fail 2]"))
+	rd.SetTag("fail", func(form sabre.Value) (sabre.Value, error) {
+		return failing{}, nil
+	})
+
+	mod, err := rd.All()
+	if err != nil {
+		t.Fatalf("All() unexpected error: %v", err)
+	}
+
+	_, err = sabre.Eval(sabre.New(), mod)
+	err = rd.SourceMap().Annotate(err)
+
+	var ee sabre.EvalError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected EvalError, got %#v", err)
+	}
+
+	want := sabre.Span{
+		File:  "<string>",
+		Start: sabre.Location{Offset: 1, Line: 1, Column: 2},
+		End:   sabre.Location{Offset: 8, Line: 1, Column: 9},
+	}
+	if ee.Span != want || ee.Line != 1 || ee.Column != 2 {
+		t.Errorf("Span got = %s (position %s), want = %s", ee.Span, ee.Position, want)
+	}
+}
+
+func TestEvalError_Position(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name          string
+		src           string
+		annotate      bool
+		line, column  int
+		spanEndColumn int
+	}{
+		{name: "SymbolInVector", src: "(do 1\n [2 undefined])", line: 2, column: 5},
+		{name: "SymbolInHashMap", src: "{:a 1\n :b undefined}", line: 2, column: 5},
+		{name: "AtomInVector", src: "(do 1\n [2 #fail 0])", line: 2, column: 2},
+		{name: "AtomInHashMap", src: "(do 1\n {:a #fail 0})", line: 2, column: 2},
+		{name: "AtomInVectorAnnotated", src: "(do 1\n [2 #fail 0])", annotate: true,
+			line: 2, column: 5, spanEndColumn: 12},
+		{name: "AtomInSetAnnotated", src: "#{1\n #fail 0}", annotate: true,
+			line: 2, column: 2, spanEndColumn: 9},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			rd := sabre.NewReader(strings.NewReader(tt.src))
+			rd.SetTag("fail", func(form sabre.Value) (sabre.Value, error) {
+				return failing{}, nil
+			})
+
+			form, err := rd.One()
+			if err != nil {
+				t.Fatalf("One() unexpected error: %v", err)
+			}
+
+			_, err = sabre.Eval(sabre.New(), form)
+			if tt.annotate {
+				err = rd.SourceMap().Annotate(err)
+			}
+
+			var ee sabre.EvalError
+			if !errors.As(err, &ee) {
+				t.Fatalf("expected EvalError, got %#v", err)
+			}
+
+			if ee.Line != tt.line || ee.Column != tt.column {
+				t.Errorf("expected error at %d:%d, got %d:%d", tt.line, tt.column, ee.Line, ee.Column)
+			}
+
+			if ee.Span.End.Column != tt.spanEndColumn {
+				t.Errorf("expected span to end at column %d, got %s", tt.spanEndColumn, ee.Span)
+			}
+		})
+	}
+}
+
+// failing is a form without position that fails to evaluate.
+type failing struct{}
+
+func (failing) String() string { return "failing" }
+
+func (failing) Eval(_ sabre.Scope) (sabre.Value, error) {
+	return nil, errors.New("failed")
+}
+
 type readerTestCase struct {
 	name    string
 	src     string
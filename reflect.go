@@ -15,9 +15,20 @@ var (
 // type, it is returned as is. Primitive Go values like string, rune, int, float,
 // bool are converted to the right sabre Value types. Functions are converted to
 // the wrapper 'Fn' type. Value of type 'reflect.Type' will be wrapped as 'Type'
-// which enables initializing a value of that type when invoked. All other types
-// will be wrapped using 'Any' type.
+// which enables initializing a value of that type when invoked. Byte slices
+// are converted to String. Other slices, arrays and maps are converted to
+// Vector and HashMap recursively, unless their type has methods. This copies
+// every item upfront, which is also done for slices and maps returned from
+// functions. Use View() (or return a Value created using View() from bound
+// functions) to convert lazily instead. All other types will be wrapped using
+// 'Any' type.
 func ValueOf(v interface{}) Value {
+	return valueOf(v, false)
+}
+
+// valueOf converts the Go value similar to ValueOf(). If structs is true,
+// structs without methods are also converted to HashMap. See StructToMap().
+func valueOf(v interface{}, structs bool) Value {
 	if v == nil {
 		return Nil{}
 	}
@@ -51,8 +62,32 @@ func ValueOf(v interface{}) Value {
 	case reflect.Bool:
 		return Bool(rv.Bool())
 
+	case reflect.Chan:
+		return &Chan{ch: rv}
+
+	case reflect.Slice, reflect.Array:
+		if rv.Type().NumMethod() > 0 {
+			return Any{V: rv}
+		}
+
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return String(rv.Bytes())
+		}
+		return vectorOf(rv, structs)
+
+	case reflect.Map:
+		if rv.Type().NumMethod() > 0 {
+			return Any{V: rv}
+		}
+		return hashMapOf(rv, structs)
+
+	case reflect.Struct:
+		if !structs || !isPlainStruct(rv.Type()) {
+			return Any{V: rv}
+		}
+		return structToMap(rv)
+
 	default:
-		// TODO: handle array & slice as list/vector.
 		return Any{V: rv}
 	}
 }
@@ -63,7 +98,27 @@ type Any struct{ V reflect.Value }
 // Eval returns itself.
 func (any Any) Eval(_ Scope) (Value, error) { return any, nil }
 
-func (any Any) String() string { return fmt.Sprintf("Any{%v}", any.V) }
+func (any Any) String() string {
+	if _, isTime := anyTime(any); isTime {
+		return PrStr(any)
+	}
+	return fmt.Sprintf("Any{%v}", any.V)
+}
+
+// Compare returns true if 'v' is also an Any value wrapping an equivalent
+// Go value.
+func (any Any) Compare(v Value) bool {
+	other, ok := v.(Any)
+	if !ok || !any.V.IsValid() || !other.V.IsValid() {
+		return false
+	}
+
+	if !any.V.CanInterface() || !other.V.CanInterface() {
+		return false
+	}
+
+	return reflect.DeepEqual(any.V.Interface(), other.V.Interface())
+}
 
 // Type represents the type value of a given value. Type also implements
 // Value type.
@@ -74,7 +129,17 @@ func (t Type) Eval(_ Scope) (Value, error) { return t, nil }
 
 func (t Type) String() string { return fmt.Sprintf("%v", t.T) }
 
-// Invoke creates zero value of the given type.
+// Invoke creates a value of the given type using the arguments. Without
+// arguments, returns the zero value of the type. Structs are initialized
+// using a hash-map of field values (e.g., (User {:name "bob"})) or values
+// for all the fields in order (e.g., (User "bob" 30)). Numbers and strings
+// are converted with overflow checks (e.g., (int32 10)), slices and arrays
+// are created from the arguments or a sequence and maps from a hash-map or
+// key-value pairs. If the type is a pointer type, returns pointer to the
+// value created for the element type. The created value is converted using
+// ValueOf(), so numbers are widened (e.g., (int32 10) returns Int64 after
+// checking that 10 fits in int32) and are converted back to the Go type when
+// passed to bound functions.
 func (t Type) Invoke(scope Scope, args ...Value) (Value, error) {
 	if isKind(t.T, reflect.Interface, reflect.Chan, reflect.Func) {
 		return nil, fmt.Errorf("type '%s' cannot be initialized", t.T)
@@ -96,12 +161,12 @@ func (t Type) Invoke(scope Scope, args ...Value) (Value, error) {
 		return Set{Values: Values(argVals).Uniq()}, nil
 	}
 
-	likeSeq := isKind(t.T, reflect.Slice, reflect.Array)
-	if likeSeq {
-		return Values(argVals), nil
+	rv, err := construct(t.T, argVals)
+	if err != nil {
+		return nil, err
 	}
 
-	return ValueOf(reflect.New(t.T).Elem().Interface()), nil
+	return ValueOf(rv.Interface()), nil
 }
 
 // reflectFn creates a wrapper Fn for the given Go function value using
@@ -187,7 +252,13 @@ func (fw *funcWrapper) argNames() []string {
 
 	var argNames []string
 
+	// scope is passed implicitly and is not an argument of the function
+	// from the caller's perspective.
 	i := 0
+	if fw.passScope {
+		i = 1
+	}
+
 	for ; i < fw.minArgs; i++ {
 		argNames = append(argNames, cleanArgName(fw.rt.In(i)))
 	}
@@ -267,25 +338,79 @@ func (fw *funcWrapper) wrapReturns(vals ...reflect.Value) (Value, error) {
 func convertArgsTo(expected reflect.Type, args ...reflect.Value) ([]reflect.Value, error) {
 	var converted []reflect.Value
 	for _, arg := range args {
-		actual := arg.Type()
-		switch {
-		case isAssignable(actual, expected):
-			converted = append(converted, arg)
-
-		case actual.ConvertibleTo(expected):
-			converted = append(converted, arg.Convert(expected))
-
-		default:
-			return args, fmt.Errorf(
-				"value of type '%s' cannot be converted to '%s'",
-				actual, expected,
-			)
+		c, err := convertArgTo(expected, arg)
+		if err != nil {
+			return args, err
 		}
+		converted = append(converted, c)
 	}
 
 	return converted, nil
 }
 
+func convertArgTo(expected reflect.Type, arg reflect.Value) (reflect.Value, error) {
+	actual := arg.Type()
+	if isAssignable(actual, expected) {
+		return arg, nil
+	}
+
+	var v interface{}
+	if arg.CanInterface() {
+		v = arg.Interface()
+	}
+
+	if gv, ok := v.(goValuer); ok {
+		if c, err := convertArgTo(expected, gv.goValue()); err == nil {
+			return c, nil
+		}
+	}
+
+	if _, isValue := v.(Value); !isValue && arg.CanInterface() {
+		// Go values unwrapped from Any are wrapped again when a Value is
+		// expected.
+		if val := ValueOf(v); isAssignable(reflect.TypeOf(val), expected) {
+			return reflect.ValueOf(val), nil
+		}
+	}
+
+	// integers are convertible to strings in Go but the result is the UTF-8
+	// encoding of the integer as a code point, not its text.
+	isRuneString := expected.Kind() == reflect.String &&
+		isKind(actual, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64)
+	if actual.ConvertibleTo(expected) && !isRuneString {
+		return arg.Convert(expected), nil
+	}
+
+	if val, ok := v.(Value); ok {
+		switch expected.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return convertCollection(expected, val)
+
+		case reflect.Struct:
+			return convertStruct(expected, val)
+
+		case reflect.Ptr:
+			if _, isNil := val.(Nil); isNil {
+				return reflect.Zero(expected), nil
+			}
+
+			elem, err := convertArgTo(expected.Elem(), arg)
+			if err != nil {
+				return arg, err
+			}
+
+			ptr := reflect.New(expected.Elem())
+			ptr.Elem().Set(elem)
+			return ptr, nil
+		}
+	}
+
+	return arg, fmt.Errorf(
+		"value of type '%s' cannot be converted to '%s'",
+		actual, expected,
+	)
+}
+
 func isAssignable(from, to reflect.Type) bool {
 	return (from == to) || from.AssignableTo(to) ||
 		(to.Kind() == reflect.Interface && from.Implements(to))
@@ -12,6 +12,12 @@ var simpleFnRV = reflect.ValueOf(simpleFn)
 var anyVal = struct{ name string }{}
 var anyValRV = reflect.ValueOf(anyVal)
 
+type sortedInts []int
+
+func (si sortedInts) Len() int { return len(si) }
+
+var sortedVal interface{} = sortedInts{1}
+
 func TestValueOf(t *testing.T) {
 	t.Parallel()
 
@@ -60,6 +66,29 @@ func TestValueOf(t *testing.T) {
 			v:    anyVal,
 			want: Any{V: anyValRV},
 		},
+		{
+			name: "Slice",
+			v:    []string{"a", "b"},
+			want: Vector{Values: Values{String("a"), String("b")}},
+		},
+		{
+			name: "Array",
+			v:    [2][]int{{1}, {2, 3}},
+			want: Vector{Values: Values{
+				Vector{Values: Values{Int64(1)}},
+				Vector{Values: Values{Int64(2), Int64(3)}},
+			}},
+		},
+		{
+			name: "Map",
+			v:    map[string]int{"a": 1},
+			want: &HashMap{Data: map[Value]Value{Keyword("a"): Int64(1)}},
+		},
+		{
+			name: "SliceWithMethods",
+			v:    sortedVal,
+			want: Any{V: reflect.ValueOf(sortedVal)},
+		},
 	}
 
 	for _, tt := range table {
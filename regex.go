@@ -0,0 +1,286 @@
+package sabre
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxCachedRegex is the number of compiled patterns retained by the regex
+// cache before it is reset.
+const maxCachedRegex = 1024
+
+var regexCache = &patternCache{patterns: map[string]*regexp.Regexp{}}
+
+// CompileRegex compiles the pattern into a Regex value. Compiled patterns
+// are cached, so compiling the same pattern repeatedly (e.g., a regex literal
+// in a rule that is read and evaluated for every request) is cheap.
+func CompileRegex(pattern string) (Regex, error) {
+	re, err := regexCache.compile(pattern)
+	if err != nil {
+		return Regex{}, fmt.Errorf("invalid regex: %v", err)
+	}
+
+	return Regex{Regexp: re}, nil
+}
+
+// Regex represents a compiled regular expression. Regex literals can be
+// written using '#"pattern"' syntax.
+type Regex struct {
+	*regexp.Regexp
+}
+
+// Eval returns itself.
+func (re Regex) Eval(_ Scope) (Value, error) { return re, nil }
+
+// Invoke finds the first match of the regex in the string argument. See
+// Regex.Find().
+func (re Regex) Invoke(scope Scope, args ...Value) (Value, error) {
+	if err := verifyArgCount([]int{1}, args); err != nil {
+		return nil, err
+	}
+
+	argVals, err := evalValueList(scope, args)
+	if err != nil {
+		return nil, err
+	}
+
+	s, isString := argVals[0].(String)
+	if !isString {
+		return nil, fmt.Errorf("regex can be invoked only with string, not '%s'",
+			reflect.TypeOf(argVals[0]))
+	}
+
+	return re.Find(string(s)), nil
+}
+
+// Find returns the first match of the regex in s. If the regex has capture
+// groups, the result is a vector of the whole match followed by the groups.
+// Returns nil if there is no match.
+func (re Regex) Find(s string) Value {
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return Nil{}
+	}
+	return matchValue(s, loc)
+}
+
+// Matches is same as Find() but the regex must match the whole of s.
+func (re Regex) Matches(s string) (Value, error) {
+	full, err := regexCache.compile(`^(?:` + re.Regexp.String() + `)\z`)
+	if err != nil {
+		return nil, err
+	}
+	return Regex{Regexp: full}.Find(s), nil
+}
+
+// Seq returns a list of all successive matches of the regex in s. Returns
+// nil if there are no matches.
+func (re Regex) Seq(s string) Value {
+	locs := re.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return Nil{}
+	}
+
+	var matches Values
+	for _, loc := range locs {
+		matches = append(matches, matchValue(s, loc))
+	}
+	return &List{Values: matches}
+}
+
+// Groups returns a vector of the whole match followed by capture groups of
+// the first match of the regex in s. Returns nil if there is no match.
+func (re Regex) Groups(s string) Value {
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return Nil{}
+	}
+	return Vector{Values: submatches(s, loc)}
+}
+
+// Compare returns true if 'v' is also a Regex with the same pattern.
+func (re Regex) Compare(v Value) bool {
+	other, ok := v.(Regex)
+	if !ok || re.Regexp == nil || other.Regexp == nil {
+		return ok && re.Regexp == other.Regexp
+	}
+
+	return re.Regexp.String() == other.Regexp.String()
+}
+
+func (re Regex) String() string {
+	var sb strings.Builder
+	sb.WriteString(`#"`)
+
+	escaped := false
+	for _, r := range re.Regexp.String() {
+		if r == '"' && !escaped {
+			sb.WriteRune('\\')
+		}
+		escaped = r == '\\' && !escaped
+		sb.WriteRune(r)
+	}
+
+	sb.WriteRune('"')
+	return sb.String()
+}
+
+func bindRegex(scope Scope) {
+	_ = scope.Bind("re-pattern", ValueOf(func(v Value) (Regex, error) {
+		return toRegex(v)
+	}))
+
+	_ = scope.Bind("re-find", ValueOf(func(pattern Value, s String) (Value, error) {
+		re, err := toRegex(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.Find(string(s)), nil
+	}))
+
+	_ = scope.Bind("re-matches", ValueOf(func(pattern Value, s String) (Value, error) {
+		re, err := toRegex(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.Matches(string(s))
+	}))
+
+	_ = scope.Bind("re-seq", ValueOf(func(pattern Value, s String) (Value, error) {
+		re, err := toRegex(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.Seq(string(s)), nil
+	}))
+
+	_ = scope.Bind("re-groups", ValueOf(func(pattern Value, s String) (Value, error) {
+		re, err := toRegex(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.Groups(string(s)), nil
+	}))
+
+	_ = scope.Bind("replace", ValueOf(replace))
+}
+
+// replace replaces all occurrences of match in s with replacement. match can
+// be a string, character or a regex. If match is a regex, replacement can be
+// a string with '$1' or '${name}' group references or a function that will
+// be invoked with each match (see Regex.Find()) and should return a string.
+func replace(scope Scope, s String, match, replacement Value) (String, error) {
+	switch m := match.(type) {
+	case String:
+		r, isString := replacement.(String)
+		if !isString {
+			return "", fmt.Errorf("replacement for string must be string, not '%s'",
+				reflect.TypeOf(replacement))
+		}
+		return String(strings.Replace(string(s), string(m), string(r), -1)), nil
+
+	case Character:
+		r, isChar := replacement.(Character)
+		if !isChar {
+			return "", fmt.Errorf("replacement for character must be character, not '%s'",
+				reflect.TypeOf(replacement))
+		}
+		return String(strings.Replace(string(s), string(m), string(r), -1)), nil
+
+	case Regex:
+		switch r := replacement.(type) {
+		case String:
+			return String(m.ReplaceAllString(string(s), string(r))), nil
+
+		case Invokable:
+			return replaceFunc(scope, m, string(s), r)
+		}
+
+		return "", fmt.Errorf("replacement for regex must be string or fn, not '%s'",
+			reflect.TypeOf(replacement))
+	}
+
+	return "", fmt.Errorf("match must be string, character or regex, not '%s'",
+		reflect.TypeOf(match))
+}
+
+func replaceFunc(scope Scope, re Regex, s string, fn Invokable) (String, error) {
+	var sb strings.Builder
+
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		v, err := fn.Invoke(scope, matchValue(s, loc))
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(s[last:loc[0]])
+		sb.WriteString(Str(v))
+		last = loc[1]
+	}
+	sb.WriteString(s[last:])
+
+	return String(sb.String()), nil
+}
+
+func toRegex(v Value) (Regex, error) {
+	switch p := v.(type) {
+	case Regex:
+		return p, nil
+
+	case String:
+		return CompileRegex(string(p))
+	}
+
+	return Regex{}, fmt.Errorf("expecting regex or string, not '%s'", reflect.TypeOf(v))
+}
+
+func matchValue(s string, loc []int) Value {
+	if len(loc) == 2 {
+		return String(s[loc[0]:loc[1]])
+	}
+	return Vector{Values: submatches(s, loc)}
+}
+
+func submatches(s string, loc []int) Values {
+	vals := make(Values, 0, len(loc)/2)
+	for i := 0; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			vals = append(vals, Nil{})
+		} else {
+			vals = append(vals, String(s[loc[i]:loc[i+1]]))
+		}
+	}
+	return vals
+}
+
+type patternCache struct {
+	mu       sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}
+
+func (pc *patternCache) compile(pattern string) (*regexp.Regexp, error) {
+	pc.mu.RLock()
+	re, found := pc.patterns[pattern]
+	pc.mu.RUnlock()
+	if found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if len(pc.patterns) >= maxCachedRegex {
+		pc.patterns = map[string]*regexp.Regexp{}
+	}
+	pc.patterns[pattern] = re
+
+	return re, nil
+}
@@ -0,0 +1,131 @@
+package sabre_test
+
+import (
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestRegex_Eval(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr bool
+	}{
+		{
+			name: "Invoke",
+			src:  `(#"\d+" "abc123def45")`,
+			want: sabre.String("123"),
+		},
+		{
+			name: "InvokeNoMatch",
+			src:  `(#"\d+" "abc")`,
+			want: sabre.Nil{},
+		},
+		{
+			name:    "InvokeNonString",
+			src:     `(#"\d+" 10)`,
+			wantErr: true,
+		},
+		{
+			name: "FindWithGroups",
+			src:  `(re-find #"(\w+)@(\w+)?" "mail bob@ now")`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.String("bob@"), sabre.String("bob"), sabre.Nil{},
+			}},
+		},
+		{
+			name: "MatchesWhole",
+			src:  `(re-matches #"a|ab" "ab")`,
+			want: sabre.String("ab"),
+		},
+		{
+			name: "MatchesPartial",
+			src:  `(re-matches #"\d+" "123abc")`,
+			want: sabre.Nil{},
+		},
+		{
+			name: "Seq",
+			src:  `(re-seq #"\d" "a1b2c3")`,
+			want: &sabre.List{Values: sabre.Values{
+				sabre.String("1"), sabre.String("2"), sabre.String("3"),
+			}},
+		},
+		{
+			name: "SeqNoMatch",
+			src:  `(re-seq #"\d" "abc")`,
+			want: sabre.Nil{},
+		},
+		{
+			name: "Groups",
+			src:  `(re-groups (re-pattern "(?P<key>\\w+)=(\\w+)") "a=b")`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.String("a=b"), sabre.String("a"), sabre.String("b"),
+			}},
+		},
+		{
+			name:    "InvalidPattern",
+			src:     `(re-pattern "[a-")`,
+			wantErr: true,
+		},
+		{
+			name: "ReplaceString",
+			src:  `(replace "a.b.c" "." "/")`,
+			want: sabre.String("a/b/c"),
+		},
+		{
+			name: "ReplaceCharacter",
+			src:  `(replace "a.b.c" \. \-)`,
+			want: sabre.String("a-b-c"),
+		},
+		{
+			name: "ReplaceGroups",
+			src:  `(replace "john smith" #"(?P<first>\w+) (\w+)" "$2, ${first}")`,
+			want: sabre.String("smith, john"),
+		},
+		{
+			name: "ReplaceFn",
+			src:  `(replace "a1b22" #"\d+" (fn* [m] (str "<" m ">")))`,
+			want: sabre.String("a<1>b<22>"),
+		},
+		{
+			name:    "ReplaceMismatch",
+			src:     `(replace "abc" "a" \b)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sabre.ReadEvalStr(sabre.New(), tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !sabre.Compare(got, tt.want) {
+				t.Errorf("Eval() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRegex_Cached(t *testing.T) {
+	t.Parallel()
+
+	re1, err := sabre.CompileRegex(`cached-\d+`)
+	if err != nil {
+		t.Fatalf("CompileRegex() unexpected error: %v", err)
+	}
+
+	re2, err := sabre.CompileRegex(`cached-\d+`)
+	if err != nil {
+		t.Fatalf("CompileRegex() unexpected error: %v", err)
+	}
+
+	if re1.Regexp != re2.Regexp {
+		t.Errorf("expected same compiled regex to be reused")
+	}
+}
@@ -0,0 +1,314 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+// Command represents a REPL meta-command. Commands are invoked by entering
+// ':name' followed by arguments on a single line (e.g., ':doc inc'). Input
+// starting with ':' that does not name a registered command is evaluated as
+// usual. See WithCommands().
+type Command struct {
+	Name string // name used for invoking without ':' prefix.
+	Args string // synopsis of arguments displayed by ':help'.
+	Help string // one line description displayed by ':help'.
+
+	// Run executes the command with the rest of the line as args. Output
+	// should be written to the REPL (See REPL.Write()). Returned errors are
+	// printed, except io.EOF which ends the REPL session.
+	Run func(ctx context.Context, repl *REPL, args string) error
+}
+
+// WithCommands registers meta-commands with the REPL. Commands with the same
+// name as a previously registered command replace it. Use DefaultCommands()
+// to enable the built-in commands.
+func WithCommands(cmds ...Command) Option {
+	return func(repl *REPL) {
+		if repl.commands == nil {
+			repl.commands = map[string]Command{}
+		}
+
+		for _, cmd := range cmds {
+			repl.commands[cmd.Name] = cmd
+		}
+	}
+}
+
+// DefaultCommands returns the built-in meta-commands:
+//
+//	:help                list available commands
+//	:doc <symbol>        describe the value bound to the symbol
+//	:time <form>         evaluate the form and print the time taken
+//	:macroexpand <form>  expand the form until it is not a macro call
+//	:scope [prefix]      list symbols available in the scope
+//	:load <path>         read and evaluate a source file
+//	:unbind <symbol>     remove the binding from the scope
+//	:reset               restore the scope to its state at start
+//	:quit                end the REPL session
+func DefaultCommands() []Command {
+	return []Command{
+		{Name: "help", Help: "List available commands", Run: cmdHelp},
+		{Name: "doc", Args: "<symbol>", Help: "Describe the value bound to the symbol", Run: cmdDoc},
+		{Name: "time", Args: "<form>", Help: "Evaluate the form and print the time taken", Run: cmdTime},
+		{Name: "macroexpand", Args: "<form>", Help: "Expand the form until it is not a macro call", Run: cmdMacroExpand},
+		{Name: "scope", Args: "[prefix]", Help: "List symbols available in the scope", Run: cmdScope},
+		{Name: "load", Args: "<path>", Help: "Read and evaluate a source file", Run: cmdLoad},
+		{Name: "unbind", Args: "<symbol>", Help: "Remove the binding from the scope", Run: cmdUnbind},
+		{Name: "reset", Help: "Restore the scope to its state at start", Run: cmdReset},
+		{Name: "quit", Help: "End the REPL session", Run: cmdQuit},
+	}
+}
+
+type commandCall struct {
+	cmd  Command
+	args string
+}
+
+// command returns the invocation if the line invokes a registered command.
+func (repl *REPL) command(line string) *commandCall {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ":") {
+		return nil
+	}
+
+	name, args := line[1:], ""
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name, args = name[:i], strings.TrimSpace(name[i:])
+	}
+
+	cmd, found := repl.commands[name]
+	if !found {
+		return nil
+	}
+
+	return &commandCall{cmd: cmd, args: args}
+}
+
+func cmdHelp(_ context.Context, repl *REPL, _ string) error {
+	var names []string
+	for name := range repl.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines [][2]string
+	width := 0
+	for _, name := range names {
+		cmd := repl.commands[name]
+		usage := strings.TrimSpace(":" + name + " " + cmd.Args)
+		if len(usage) > width {
+			width = len(usage)
+		}
+		lines = append(lines, [2]string{usage, cmd.Help})
+	}
+
+	for _, line := range lines {
+		fmt.Fprintf(repl, "%-*s  %s\n", width, line[0], line[1])
+	}
+	return nil
+}
+
+func cmdDoc(_ context.Context, repl *REPL, args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: :doc <symbol>")
+	}
+
+	v, err := repl.scope.Resolve(args)
+	if err != nil {
+		if v, err = (sabre.Symbol{Value: args}).Eval(repl.scope); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range describe(args, v) {
+		fmt.Fprintln(repl, line)
+	}
+	return nil
+}
+
+func cmdTime(ctx context.Context, repl *REPL, args string) error {
+	form, err := repl.readForm(args)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	v, err := repl.eval(ctx, form)
+	took := time.Since(start)
+	if err != nil {
+		return err
+	}
+
+	if err := repl.printResult(form, v, took); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(repl, "Elapsed time: %s\n", took)
+	return err
+}
+
+func cmdMacroExpand(_ context.Context, repl *REPL, args string) error {
+	form, err := repl.readForm(args)
+	if err != nil {
+		return err
+	}
+
+	expanded := form
+	for {
+		f, ok, err := sabre.MacroExpand(repl.scope, expanded)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			break
+		}
+		expanded = f
+	}
+
+	return repl.printResult(form, expanded, 0)
+}
+
+func cmdScope(_ context.Context, repl *REPL, args string) error {
+	completions := Complete(repl.scope, args)
+
+	width := 0
+	for _, c := range completions {
+		if len(c.Candidate) > width {
+			width = len(c.Candidate)
+		}
+	}
+
+	for _, c := range completions {
+		fmt.Fprintf(repl, "%-*s  %s\n", width, c.Candidate, c.Type)
+	}
+	return nil
+}
+
+func cmdLoad(ctx context.Context, repl *REPL, args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: :load <path>")
+	}
+
+	src, err := ioutil.ReadFile(args)
+	if err != nil {
+		return err
+	}
+
+	rd := repl.factory.NewReader(bytes.NewReader(src))
+	rd.File = args
+
+	form, err := rd.All()
+	if err != nil {
+		return err
+	}
+	repl.spans = rd.SourceMap()
+
+	start := time.Now()
+	v, err := repl.eval(ctx, form)
+	if err != nil {
+		return err
+	}
+
+	return repl.printResult(form, v, time.Since(start))
+}
+
+func cmdUnbind(_ context.Context, repl *REPL, args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: :unbind <symbol>")
+	}
+
+	unbinder, ok := repl.scope.(sabre.Unbinder)
+	if !ok {
+		return fmt.Errorf("scope does not support unbinding")
+	}
+	return unbinder.Unbind(args)
+}
+
+func cmdReset(_ context.Context, repl *REPL, _ string) error {
+	if repl.snapshot != nil {
+		if err := repl.scope.(sabre.Snapshotter).Restore(repl.snapshot); err != nil {
+			return err
+		}
+	}
+
+	repl.resetResults()
+	return nil
+}
+
+func cmdQuit(context.Context, *REPL, string) error {
+	return io.EOF
+}
+
+// readForm reads exactly one form from the command arguments.
+func (repl *REPL) readForm(src string) (sabre.Value, error) {
+	rd := repl.factory.NewReader(strings.NewReader(src))
+	rd.File = "REPL"
+
+	form, err := rd.All()
+	if err != nil {
+		return nil, err
+	}
+
+	switch mod := form.(sabre.Module); len(mod) {
+	case 0:
+		return nil, fmt.Errorf("expecting a form")
+
+	case 1:
+		repl.spans = rd.SourceMap()
+		return mod[0], nil
+
+	default:
+		return nil, fmt.Errorf("expecting exactly one form")
+	}
+}
+
+// describe returns signatures and type information of the value bound to
+// the symbol.
+func describe(name string, v sabre.Value) []string {
+	switch val := v.(type) {
+	case sabre.SpecialForm:
+		return []string{"(" + name + " ...)", "  special form"}
+
+	case sabre.MultiFn:
+		var lines []string
+		for _, method := range val.Methods {
+			lines = append(lines, call(name, method.String()))
+		}
+
+		if val.IsMacro {
+			return append(lines, "  macro")
+		}
+		return append(lines, "  function")
+
+	case *sabre.Fn:
+		return []string{call(name, val.String()), "  function"}
+
+	case sabre.Type:
+		return []string{name, "  type " + val.T.String()}
+
+	case sabre.Any:
+		if val.V.IsValid() {
+			return []string{name, "  " + val.V.Type().String()}
+		}
+	}
+
+	return []string{name, "  " + sabre.PrStr(v)}
+}
+
+func call(name, args string) string {
+	args = strings.TrimSpace(strings.Trim(args, "()"))
+	if args == "" {
+		return "(" + name + ")"
+	}
+	return "(" + name + " " + args + ")"
+}
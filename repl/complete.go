@@ -0,0 +1,86 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spy16/sabre"
+)
+
+// Completion represents a candidate for completing a partial symbol.
+type Completion struct {
+	Candidate string
+	Type      string // one of function, macro, special-form, var, method, field.
+}
+
+// Complete returns completions for the prefix sorted by candidate. Symbols
+// bound in the scope and its parents are used as candidates if the scopes
+// implement sabre.BindingLister (See sabre.Bindings()). For
+// qualified prefixes like 'foo.Ba', the members of the value bound to 'foo'
+// are used as candidates (See sabre.Members()).
+func Complete(scope sabre.Scope, prefix string) []Completion {
+	if dot := strings.LastIndex(prefix, "."); dot > 0 {
+		return completeMember(scope, prefix[:dot], prefix[dot+1:])
+	}
+
+	var completions []Completion
+	for symbol, v := range sabre.Bindings(scope) {
+		if strings.HasPrefix(symbol, prefix) {
+			completions = append(completions, Completion{
+				Candidate: symbol,
+				Type:      valueType(v),
+			})
+		}
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[i].Candidate < completions[j].Candidate
+	})
+	return completions
+}
+
+func completeMember(scope sabre.Scope, target, prefix string) []Completion {
+	v, err := sabre.Symbol{Value: target}.Eval(scope)
+	if err != nil {
+		return nil
+	}
+
+	var completions []Completion
+	for _, member := range sabre.Members(v) {
+		if !strings.HasPrefix(member, prefix) {
+			continue
+		}
+
+		typ := "field"
+		if mv, err := (sabre.Symbol{Value: target + "." + member}).Eval(scope); err == nil {
+			if _, isFn := mv.(*sabre.Fn); isFn {
+				typ = "method"
+			}
+		}
+
+		completions = append(completions, Completion{
+			Candidate: target + "." + member,
+			Type:      typ,
+		})
+	}
+
+	return completions
+}
+
+func valueType(v sabre.Value) string {
+	switch val := v.(type) {
+	case sabre.SpecialForm:
+		return "special-form"
+
+	case sabre.MultiFn:
+		if val.IsMacro {
+			return "macro"
+		}
+		return "function"
+
+	case *sabre.Fn:
+		return "function"
+	}
+
+	return "var"
+}
@@ -0,0 +1,646 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by LineEditor when the user presses Ctrl-C.
+// REPL discards the form being entered when Input returns this error.
+var ErrInterrupted = errors.New("interrupted")
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEsc       = 27
+	keyBackspace = 127
+)
+
+// EditorOption implementations can be provided to NewLineEditor() to
+// configure the editor.
+type EditorOption func(ed *LineEditor)
+
+// WithHistoryFile sets the file used for persisting history. Existing
+// entries are loaded from the file and entered forms are appended to it.
+// History is best-effort: errors reading or writing the file are ignored.
+func WithHistoryFile(path string) EditorOption {
+	return func(ed *LineEditor) {
+		ed.history.file = path
+	}
+}
+
+// WithHistorySize sets the maximum number of entries retained in history.
+func WithHistorySize(size int) EditorOption {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	return func(ed *LineEditor) {
+		ed.history.size = size
+	}
+}
+
+// WithCompleter sets the function used for completing the symbol before the
+// cursor when Tab is pressed. The function must return full candidates that
+// start with the prefix.
+func WithCompleter(complete func(prefix string) []string) EditorOption {
+	return func(ed *LineEditor) {
+		ed.complete = complete
+	}
+}
+
+// NewLineEditor returns an Input that provides line editing when 'in' is a
+// terminal. Supported keys include arrows, Home/End, Ctrl-A/E/B/F/K/U/W/L,
+// Up/Down or Ctrl-P/N for history, Ctrl-R for reverse history search and Tab
+// for completion. While typing, the bracket matching the closing bracket
+// before the cursor is highlighted and mismatched brackets are shown in red.
+// If 'in' is not a terminal, lines are read without editing.
+func NewLineEditor(in io.Reader, out io.Writer, opts ...EditorOption) *LineEditor {
+	ed := &LineEditor{
+		rd:      bufio.NewReader(in),
+		out:     out,
+		history: &history{size: DefaultHistorySize},
+	}
+
+	if f, ok := in.(interface{ Fd() uintptr }); ok && isTerminal(f.Fd()) {
+		ed.fd, ed.terminal = f.Fd(), true
+	}
+
+	for _, opt := range opts {
+		opt(ed)
+	}
+
+	if ed.history.file != "" {
+		_ = ed.history.load()
+	}
+
+	return ed
+}
+
+// LineEditor implements Input with a raw-mode terminal line editor.
+type LineEditor struct {
+	rd       *bufio.Reader
+	out      io.Writer
+	fd       uintptr
+	terminal bool
+	prompt   string
+	history  *history
+	complete func(prefix string) []string
+
+	// pending holds the previous lines of a form spanning multiple lines.
+	pending string
+}
+
+// SetPrompt sets the prompt displayed by the next Readline.
+func (ed *LineEditor) SetPrompt(prompt string) {
+	ed.prompt = prompt
+}
+
+// Readline reads a line from the input. Returns io.EOF if Ctrl-D is pressed
+// on an empty line and ErrInterrupted if Ctrl-C is pressed.
+func (ed *LineEditor) Readline() (string, error) {
+	if !ed.terminal {
+		return ed.readPlain()
+	}
+
+	restore, err := makeRaw(ed.fd)
+	if err != nil {
+		return ed.readPlain()
+	}
+	defer restore()
+
+	return ed.edit()
+}
+
+func (ed *LineEditor) readPlain() (string, error) {
+	io.WriteString(ed.out, ed.prompt)
+
+	line, err := ed.rd.ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if err != nil {
+		return line, err
+	}
+
+	return ed.accept(line), nil
+}
+
+// lineState is the state of the line being edited.
+type lineState struct {
+	buf     []rune
+	pos     int
+	histIdx int
+	draft   []rune
+}
+
+func (ed *LineEditor) edit() (string, error) {
+	st := &lineState{histIdx: len(ed.history.entries)}
+	ed.refresh(st)
+
+	lastTab := false
+	for {
+		r, _, err := ed.rd.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(st.buf) > 0 {
+				return ed.finish(st), nil
+			}
+			return "", err
+		}
+
+		tab := false
+		switch r {
+		case keyCR, keyLF:
+			return ed.finish(st), nil
+
+		case keyCtrlC:
+			io.WriteString(ed.out, "^C\n")
+			ed.pending = ""
+			return "", ErrInterrupted
+
+		case keyCtrlD:
+			if len(st.buf) == 0 {
+				io.WriteString(ed.out, "\n")
+				return "", io.EOF
+			}
+			st.deleteAt(st.pos)
+
+		case keyCtrlA:
+			st.pos = 0
+
+		case keyCtrlE:
+			st.pos = len(st.buf)
+
+		case keyCtrlB:
+			st.move(-1)
+
+		case keyCtrlF:
+			st.move(1)
+
+		case keyCtrlH, keyBackspace:
+			if st.pos > 0 {
+				st.pos--
+				st.deleteAt(st.pos)
+			}
+
+		case keyCtrlK:
+			st.buf = st.buf[:st.pos]
+
+		case keyCtrlU:
+			st.buf = append([]rune{}, st.buf[st.pos:]...)
+			st.pos = 0
+
+		case keyCtrlW:
+			st.deleteWord()
+
+		case keyCtrlL:
+			io.WriteString(ed.out, "\x1b[H\x1b[2J")
+
+		case keyCtrlP:
+			ed.historyMove(st, -1)
+
+		case keyCtrlN:
+			ed.historyMove(st, 1)
+
+		case keyCtrlR:
+			submit, err := ed.reverseSearch(st)
+			if err != nil {
+				return "", err
+			}
+			if submit {
+				return ed.finish(st), nil
+			}
+
+		case keyTab:
+			ed.completeAt(st, lastTab)
+			tab = true
+
+		case keyEsc:
+			ed.escape(st, ed.readEscape())
+
+		default:
+			if unicode.IsPrint(r) {
+				st.insert(r)
+			}
+		}
+
+		lastTab = tab
+		ed.refresh(st)
+	}
+}
+
+// finish moves to the next line and accepts the line being edited.
+func (ed *LineEditor) finish(st *lineState) string {
+	st.pos = len(st.buf)
+	ed.render(st, -1, -1)
+	io.WriteString(ed.out, "\n")
+	return ed.accept(string(st.buf))
+}
+
+// accept records the line as part of the form being entered. Once the form
+// is complete, it is added to the history.
+func (ed *LineEditor) accept(line string) string {
+	src := ed.pending + line + "\n"
+
+	depth := 0
+	inString := scanForm([]rune(src), func(_ int, r rune) {
+		if isOpenBracket(r) {
+			depth++
+		} else {
+			depth--
+		}
+	})
+
+	if depth > 0 || inString {
+		ed.pending = src
+		return line
+	}
+
+	ed.pending = ""
+	_ = ed.history.add(src)
+	return line
+}
+
+// readEscape reads the rest of an escape sequence after ESC and returns it
+// (e.g., "[A" for the up arrow).
+func (ed *LineEditor) readEscape() string {
+	r, _, err := ed.rd.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return ""
+	}
+
+	seq := []rune{r}
+	for {
+		r, _, err := ed.rd.ReadRune()
+		if err != nil {
+			return ""
+		}
+
+		seq = append(seq, r)
+		if r >= 0x40 && r <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+func (ed *LineEditor) escape(st *lineState, seq string) {
+	switch seq {
+	case "[A", "OA":
+		ed.historyMove(st, -1)
+
+	case "[B", "OB":
+		ed.historyMove(st, 1)
+
+	case "[C", "OC":
+		st.move(1)
+
+	case "[D", "OD":
+		st.move(-1)
+
+	case "[H", "OH", "[1~", "[7~":
+		st.pos = 0
+
+	case "[F", "OF", "[4~", "[8~":
+		st.pos = len(st.buf)
+
+	case "[3~":
+		st.deleteAt(st.pos)
+	}
+}
+
+// historyMove replaces the line with an older (delta < 0) or newer entry
+// from the history. The line being edited is restored when moving past the
+// newest entry.
+func (ed *LineEditor) historyMove(st *lineState, delta int) {
+	entries := ed.history.entries
+	next := st.histIdx + delta
+	if next < 0 || next > len(entries) {
+		io.WriteString(ed.out, "\a")
+		return
+	}
+
+	if st.histIdx == len(entries) {
+		st.draft = append([]rune{}, st.buf...)
+	}
+
+	st.histIdx = next
+	if next == len(entries) {
+		st.buf = append([]rune{}, st.draft...)
+	} else {
+		st.buf = []rune(entries[next])
+	}
+	st.pos = len(st.buf)
+}
+
+// reverseSearch runs an incremental search through the history. Returns
+// true if the line should be submitted.
+func (ed *LineEditor) reverseSearch(st *lineState) (bool, error) {
+	entries := ed.history.entries
+	var query []rune
+	idx, match := len(entries), ""
+
+	for {
+		fmt.Fprintf(ed.out, "\r(reverse-i-search)`%s': %s\x1b[K", string(query), match)
+
+		r, _, err := ed.rd.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case r == keyCtrlR:
+			if i := ed.history.search(string(query), idx); i >= 0 {
+				idx, match = i, entries[i]
+			} else {
+				io.WriteString(ed.out, "\a")
+			}
+
+		case r == keyCtrlG || r == keyCtrlC:
+			return false, nil
+
+		case r == keyBackspace || r == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				idx, match = len(entries), ""
+				if i := ed.history.search(string(query), idx); i >= 0 && len(query) > 0 {
+					idx, match = i, entries[i]
+				}
+			}
+
+		case r == keyCR || r == keyLF:
+			st.buf, st.pos = []rune(match), len([]rune(match))
+			return match != "", nil
+
+		case r == keyEsc:
+			ed.readEscape()
+			st.buf, st.pos = []rune(match), len([]rune(match))
+			return false, nil
+
+		case unicode.IsPrint(r):
+			query = append(query, r)
+			from := idx + 1
+			if from > len(entries) {
+				from = len(entries)
+			}
+
+			if i := ed.history.search(string(query), from); i >= 0 {
+				idx, match = i, entries[i]
+			} else {
+				io.WriteString(ed.out, "\a")
+			}
+		}
+	}
+}
+
+// completeAt completes the symbol before the cursor. If the candidates have
+// no common prefix longer than the symbol, they are listed when Tab is
+// pressed twice.
+func (ed *LineEditor) completeAt(st *lineState, list bool) {
+	start := st.pos
+	for start > 0 && !isDelimiter(st.buf[start-1]) {
+		start--
+	}
+	prefix := string(st.buf[start:st.pos])
+
+	var candidates []string
+	if ed.complete != nil {
+		candidates = ed.complete(prefix)
+	}
+
+	if len(candidates) == 0 {
+		io.WriteString(ed.out, "\a")
+		return
+	}
+
+	common := []rune(commonPrefix(candidates))
+	if len(common) > len([]rune(prefix)) {
+		rest := append(common, st.buf[st.pos:]...)
+		st.buf = append(st.buf[:start], rest...)
+		st.pos = start + len(common)
+		return
+	}
+
+	if !list {
+		io.WriteString(ed.out, "\a")
+		return
+	}
+
+	width := 80
+	if ed.terminal {
+		width = terminalWidth(ed.fd)
+	}
+
+	colWidth := 0
+	for _, c := range candidates {
+		if n := len([]rune(c)) + 2; n > colWidth {
+			colWidth = n
+		}
+	}
+
+	cols := width / colWidth
+	if cols < 1 {
+		cols = 1
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n")
+	for i, c := range candidates {
+		if i > 0 && i%cols == 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(c + strings.Repeat(" ", colWidth-len([]rune(c))))
+	}
+	sb.WriteString("\n")
+	io.WriteString(ed.out, sb.String())
+}
+
+// refresh redraws the prompt and the line with bracket highlighting.
+func (ed *LineEditor) refresh(st *lineState) {
+	open, bad := -1, -1
+	if st.pos > 0 && isCloseBracket(st.buf[st.pos-1]) {
+		open, bad = ed.matchBracket(st)
+	}
+
+	ed.render(st, open, bad)
+}
+
+func (ed *LineEditor) render(st *lineState, open, bad int) {
+	var sb strings.Builder
+	sb.WriteString("\r" + ed.prompt)
+
+	for i, r := range st.buf {
+		switch i {
+		case open:
+			sb.WriteString("\x1b[7m" + string(r) + "\x1b[0m")
+
+		case bad:
+			sb.WriteString("\x1b[31m" + string(r) + "\x1b[0m")
+
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	sb.WriteString("\x1b[K")
+	if n := len(st.buf) - st.pos; n > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", n)
+	}
+
+	io.WriteString(ed.out, sb.String())
+}
+
+// matchBracket finds the bracket matching the closing bracket before the
+// cursor taking previous lines of the form into account. Returns the index
+// of the opening bracket in the line (-1 if it is on a previous line) or the
+// index of the closing bracket as 'bad' if it has no matching opener.
+func (ed *LineEditor) matchBracket(st *lineState) (open, bad int) {
+	base := len([]rune(ed.pending))
+	src := append([]rune(ed.pending), st.buf[:st.pos]...)
+	at := len(src) - 1
+
+	var stack []int
+	matched, found := -1, false
+	scanForm(src, func(i int, r rune) {
+		if isOpenBracket(r) {
+			stack = append(stack, i)
+			return
+		}
+
+		opener := -1
+		if len(stack) > 0 && closerOf(src[stack[len(stack)-1]]) == r {
+			opener = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+
+		if i == at {
+			matched, found = opener, true
+		}
+	})
+
+	if !found {
+		return -1, -1
+	}
+
+	if matched < 0 {
+		return -1, st.pos - 1
+	}
+
+	return matched - base, -1
+}
+
+func (st *lineState) insert(r rune) {
+	st.buf = append(st.buf, 0)
+	copy(st.buf[st.pos+1:], st.buf[st.pos:])
+	st.buf[st.pos] = r
+	st.pos++
+}
+
+func (st *lineState) deleteAt(i int) {
+	if i >= 0 && i < len(st.buf) {
+		st.buf = append(st.buf[:i], st.buf[i+1:]...)
+	}
+}
+
+func (st *lineState) deleteWord() {
+	start := st.pos
+	for start > 0 && unicode.IsSpace(st.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !isDelimiter(st.buf[start-1]) {
+		start--
+	}
+
+	st.buf = append(st.buf[:start], st.buf[st.pos:]...)
+	st.pos = start
+}
+
+func (st *lineState) move(delta int) {
+	if pos := st.pos + delta; pos >= 0 && pos <= len(st.buf) {
+		st.pos = pos
+	}
+}
+
+// scanForm calls fn for every bracket in src that is not part of a string,
+// comment or character literal. Returns true if src ends inside a string.
+func scanForm(src []rune, fn func(i int, r rune)) bool {
+	inString, inComment := false, false
+	for i := 0; i < len(src); i++ {
+		r := src[i]
+
+		switch {
+		case inComment:
+			inComment = r != '\n'
+
+		case inString:
+			if r == '\\' {
+				i++
+			} else if r == '"' {
+				inString = false
+			}
+
+		case r == '"':
+			inString = true
+
+		case r == ';':
+			inComment = true
+
+		case r == '\\':
+			i++
+
+		case isOpenBracket(r) || isCloseBracket(r):
+			fn(i, r)
+		}
+	}
+
+	return inString
+}
+
+func commonPrefix(candidates []string) string {
+	prefix := []rune(candidates[0])
+	for _, c := range candidates[1:] {
+		rs := []rune(c)
+		n := 0
+		for n < len(prefix) && n < len(rs) && prefix[n] == rs[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+func closerOf(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	}
+	return 0
+}
+
+func isOpenBracket(r rune) bool  { return r == '(' || r == '[' || r == '{' }
+func isCloseBracket(r rune) bool { return r == ')' || r == ']' || r == '}' }
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()[]{}",;'`+"`~@^", r)
+}
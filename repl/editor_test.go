@@ -0,0 +1,191 @@
+package repl
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineEditor_Readline(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		history []string
+		keys    string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "Insert",
+			keys: "(+ 1 2)\r",
+			want: []string{"(+ 1 2)"},
+		},
+		{
+			name: "MoveAndEdit",
+			keys: "(+ 1 2)\x1b[D\x1b[D\x1b[D\x7f3\x01\x1b[3~[\x05\x08]\r",
+			want: []string{"[+ 3 2]"},
+		},
+		{
+			name: "KillAndWord",
+			keys: "foo bar baz\x17\x17qux\x01\x06\x06\x06\x0b\r",
+			want: []string{"foo"},
+		},
+		{
+			name:    "HistoryNavigation",
+			history: []string{"(a)", "(b)"},
+			keys:    "draft\x1b[A\x1b[A\x1b[A\x0e\x0e\r\x10\x10\x1b[B\x1b[A\r",
+			want:    []string{"draft", "(b)"},
+		},
+		{
+			name:    "ReverseSearch",
+			history: []string{"(def foo 1)", "(inc 1)", "(def bar 2)"},
+			keys:    "\x12def\x12\r",
+			want:    []string{"(def foo 1)"},
+		},
+		{
+			name:    "ReverseSearchCancel",
+			history: []string{"(def foo 1)"},
+			keys:    "x\x12def\x07\r",
+			want:    []string{"x"},
+		},
+		{
+			name: "Completion",
+			keys: "(pri\t)\r(in\t\t\x7f\x7f\r",
+			want: []string{"(println)", "("},
+		},
+		{
+			name:    "Interrupt",
+			keys:    "(foo\x03",
+			wantErr: ErrInterrupted,
+		},
+		{
+			name:    "EOF",
+			keys:    "\x04",
+			wantErr: io.EOF,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ed := newTestEditor(tt.keys, &strings.Builder{})
+			ed.history.entries = tt.history
+
+			for _, want := range tt.want {
+				got, err := ed.edit()
+				if err != nil {
+					t.Fatalf("edit() unexpected error: %v", err)
+				}
+
+				if got != want {
+					t.Errorf("edit() want=%q, got=%q", want, got)
+				}
+			}
+
+			if tt.wantErr != nil {
+				if _, err := ed.edit(); err != tt.wantErr {
+					t.Errorf("edit() want error %v, got %v", tt.wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLineEditor_BracketMatching(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	ed := newTestEditor("(foo [1 \")\"]\r)\r", &out)
+
+	if _, err := ed.edit(); err != nil {
+		t.Fatalf("edit() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "(foo \x1b[7m[\x1b[0m1") {
+		t.Errorf("expected '[' to be highlighted, got %q", out.String())
+	}
+
+	if ed.pending == "" {
+		t.Errorf("expected form to be pending")
+	}
+
+	out.Reset()
+	if _, err := ed.edit(); err != nil {
+		t.Fatalf("edit() unexpected error: %v", err)
+	}
+
+	if strings.Contains(out.String(), "\x1b[31m") {
+		t.Errorf("expected ')' matching previous line, got %q", out.String())
+	}
+
+	if got := ed.history.entries; len(got) != 1 || got[0] != `(foo [1 ")"] )` {
+		t.Errorf("expected joined form in history, got %q", got)
+	}
+
+	out.Reset()
+	ed.rd.Reset(strings.NewReader("[)\r"))
+	if _, err := ed.edit(); err != nil {
+		t.Fatalf("edit() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "[\x1b[31m)\x1b[0m") {
+		t.Errorf("expected mismatched ')' to be marked, got %q", out.String())
+	}
+}
+
+func TestLineEditor_HistoryFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "sabre-history")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "history")
+	if err := ioutil.WriteFile(file, []byte("(a)\n(b)\n(c)\n"), 0600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	ed := NewLineEditor(strings.NewReader("(d\n)\n(d )\n"), ioutil.Discard,
+		WithHistoryFile(file), WithHistorySize(3))
+
+	for i := 0; i < 3; i++ {
+		if _, err := ed.Readline(); err != nil {
+			t.Fatalf("Readline() unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+
+	want := "(a)\n(b)\n(c)\n(d )\n"
+	if string(b) != want {
+		t.Errorf("history file want=%q, got=%q", want, string(b))
+	}
+
+	reloaded := NewLineEditor(strings.NewReader(""), ioutil.Discard,
+		WithHistoryFile(file), WithHistorySize(3))
+	if got := strings.Join(reloaded.history.entries, ","); got != "(b),(c),(d )" {
+		t.Errorf("reloaded history want=%q, got=%q", "(b),(c),(d )", got)
+	}
+}
+
+func newTestEditor(keys string, out io.Writer) *LineEditor {
+	// tests call edit() directly since raw mode needs a terminal.
+	ed := NewLineEditor(strings.NewReader(keys), out, WithCompleter(func(prefix string) []string {
+		var candidates []string
+		for _, c := range []string{"println", "inc", "int"} {
+			if strings.HasPrefix(c, prefix) {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+	}))
+	return ed
+}
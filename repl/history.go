@@ -0,0 +1,99 @@
+package repl
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// DefaultHistorySize is the number of entries retained in the history of a
+// LineEditor unless configured using WithHistorySize().
+const DefaultHistorySize = 1000
+
+// history holds previously entered forms, oldest first. If a file is set,
+// entries are loaded from it and new entries are appended to it.
+type history struct {
+	file    string
+	size    int
+	entries []string
+}
+
+// load reads entries from the history file. The file is rewritten if it has
+// more entries than the history size.
+func (h *history) load() error {
+	f, err := os.Open(h.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	h.entries = lines
+	if len(lines) > h.size {
+		h.entries = lines[len(lines)-h.size:]
+		return h.save()
+	}
+
+	return nil
+}
+
+// add appends the entry unless it repeats the last entry. Entries spanning
+// multiple lines are joined into a single line.
+func (h *history) add(entry string) error {
+	lines := strings.Split(strings.TrimSpace(entry), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	entry = strings.Join(lines, " ")
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return nil
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+
+	if h.file == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(h.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry + "\n")
+	return err
+}
+
+func (h *history) save() error {
+	return ioutil.WriteFile(h.file, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+}
+
+// search returns the index of the latest entry before 'from' containing the
+// query. Returns -1 if there is no such entry.
+func (h *history) search(query string, from int) int {
+	for i := from - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
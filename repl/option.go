@@ -53,6 +53,24 @@ func WithInput(in Input, mapErr ErrMapper) Option {
 	}
 }
 
+// WithLineEditor sets a LineEditor reading from stdin as the REPL's input.
+// Tab completion uses the symbols available in the REPL's scope and members
+// of Go values for qualified symbols (See Complete()).
+func WithLineEditor(opts ...EditorOption) Option {
+	return func(repl *REPL) {
+		completer := WithCompleter(func(prefix string) []string {
+			var candidates []string
+			for _, c := range Complete(repl.scope, prefix) {
+				candidates = append(candidates, c.Candidate)
+			}
+			return candidates
+		})
+
+		repl.input = NewLineEditor(os.Stdin, os.Stdout, append([]EditorOption{completer}, opts...)...)
+		repl.mapInputErr = func(e error) error { return e }
+	}
+}
+
 // WithOutput sets the REPL's output stream.`nil` defaults to stdout.
 func WithOutput(w io.Writer) Option {
 	if w == nil {
@@ -0,0 +1,96 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+// PreplFormat represents the encoding of messages written in prepl mode.
+type PreplFormat int
+
+// Encodings supported in prepl mode.
+const (
+	// PreplEDN writes messages as EDN maps with keyword keys, one per line.
+	// (e.g., {:form "(+ 1 2)" :ms 0 :ns "user" :tag :ret :val "3"})
+	PreplEDN PreplFormat = iota
+
+	// PreplJSON writes messages as JSON objects, one per line.
+	// (e.g., {"form":"(+ 1 2)","ms":0,"ns":"user","tag":"ret","val":"3"})
+	PreplJSON
+)
+
+// WithPrepl switches the REPL to "prepl" mode meant for programmatic clients.
+// In prepl mode, banner and prompts are not displayed and every result is
+// written as a structured message instead of using the printer. Successful
+// evaluations produce a message tagged 'ret' with the printed value, the
+// form, current namespace and time taken in milliseconds. Read and eval
+// errors produce a message tagged 'err' and writes to the REPL (See Write())
+// produce a message tagged 'out'.
+func WithPrepl(format PreplFormat) Option {
+	return func(repl *REPL) {
+		repl.prepl = &prepl{format: format}
+	}
+}
+
+type prepl struct {
+	format PreplFormat
+}
+
+func (p *prepl) ret(w io.Writer, form, v sabre.Value, ns string, took time.Duration) error {
+	msg := map[string]interface{}{
+		"tag":  "ret",
+		"val":  sabre.PrStr(v),
+		"ms":   took.Milliseconds(),
+		"form": strings.TrimSpace(sabre.PrStr(form)),
+	}
+	if ns != "" {
+		msg["ns"] = ns
+	}
+
+	return p.write(w, msg)
+}
+
+func (p *prepl) err(w io.Writer, err error) error {
+	return p.write(w, map[string]interface{}{
+		"tag": "err",
+		"val": err.Error(),
+	})
+}
+
+func (p *prepl) out(w io.Writer, s string) error {
+	return p.write(w, map[string]interface{}{
+		"tag": "out",
+		"val": s,
+	})
+}
+
+func (p *prepl) write(w io.Writer, msg map[string]interface{}) error {
+	if p.format == PreplJSON {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	}
+
+	hm := &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}}
+	for key, val := range msg {
+		var v sabre.Value
+		switch key {
+		case "tag":
+			v = sabre.Keyword(val.(string))
+		default:
+			v = sabre.ValueOf(val)
+		}
+		hm.Data[sabre.Keyword(key)] = v
+	}
+
+	_, err := fmt.Fprintln(w, sabre.PrStr(hm))
+	return err
+}
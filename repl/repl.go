@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/spy16/sabre"
 )
@@ -15,14 +16,8 @@ import (
 // New returns a new instance of REPL with given sabre Scope. Option values
 // can be used to configure REPL input, output etc.
 func New(scope sabre.Scope, opts ...Option) *REPL {
-	repl := &REPL{
-		scope:            scope,
-		currentNamespace: func() string { return "" },
-	}
-
-	if ns, ok := scope.(NamespacedScope); ok {
-		repl.currentNamespace = ns.CurrentNS
-	}
+	repl := &REPL{}
+	repl.setScope(scope)
 
 	for _, option := range withDefaults(opts) {
 		option(repl)
@@ -51,7 +46,12 @@ type REPL struct {
 	prompt      string
 	multiPrompt string
 
-	printer func(io.Writer, interface{}) error
+	printer  func(io.Writer, interface{}) error
+	prepl    *prepl
+	commands map[string]Command
+	results  []sabre.Value
+	snapshot sabre.Snapshot
+	spans    *sabre.SourceMap // source map of the forms read last.
 }
 
 // Input implementation is used by REPL to read user-input. See WithInput()
@@ -62,7 +62,10 @@ type Input interface {
 }
 
 // Loop starts the read-eval-print loop. Loop runs until context is cancelled
-// or input stream returns an irrecoverable error (See WithInput()).
+// or input stream returns an irrecoverable error (See WithInput()). Forms are
+// evaluated against a scope bound to the context so that cancelling the
+// context also stops a running evaluation. Results of the last 3 evaluations
+// are bound to *1, *2 and *3 and the last error is bound to *e.
 func (repl *REPL) Loop(ctx context.Context) error {
 	repl.printBanner()
 	repl.setPrompt(false)
@@ -70,9 +73,14 @@ func (repl *REPL) Loop(ctx context.Context) error {
 	if repl.scope == nil {
 		return errors.New("scope is not set")
 	}
+	repl.resetResults()
+
+	if snapshotter, ok := repl.scope.(sabre.Snapshotter); ok {
+		repl.snapshot = snapshotter.Snapshot()
+	}
 
 	for ctx.Err() == nil {
-		err := repl.readEvalPrint()
+		err := repl.readEvalPrint(ctx)
 		if err != nil {
 			if err == io.EOF {
 				return nil
@@ -85,31 +93,92 @@ func (repl *REPL) Loop(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// Scope returns the scope used by the REPL for evaluation.
+func (repl *REPL) Scope() sabre.Scope { return repl.scope }
+
 // readEval reads one form from the input, evaluates it and prints the result.
-func (repl *REPL) readEvalPrint() error {
-	form, err := repl.read()
+func (repl *REPL) readEvalPrint(ctx context.Context) error {
+	form, call, err := repl.read()
 	if err != nil {
 		switch err.(type) {
 		case sabre.ReadError, sabre.EvalError:
-			repl.print(err)
+			return repl.printErr(err)
 		default:
 			return err
 		}
 	}
 
+	if call != nil {
+		if err := call.cmd.Run(ctx, repl, call.args); err != nil {
+			if err == io.EOF {
+				return err
+			}
+			return repl.printErr(err)
+		}
+		return nil
+	}
+
 	if form == nil {
 		return nil
 	}
 
-	v, err := sabre.Eval(repl.scope, form)
+	start := time.Now()
+	v, err := repl.eval(ctx, form)
 	if err != nil {
-		return repl.print(err)
+		return repl.printErr(err)
 	}
 
+	return repl.printResult(form, v, time.Since(start))
+}
+
+// eval evaluates the form and records the result in *1 or the error in *e.
+func (repl *REPL) eval(ctx context.Context, form sabre.Value) (sabre.Value, error) {
+	v, err := sabre.Eval(sabre.WithContext(ctx, repl.scope), form)
+	if err != nil {
+		err = repl.spans.Annotate(err)
+		repl.scope.Bind("*e", sabre.ValueOf(err))
+		return nil, err
+	}
+
+	repl.results = append([]sabre.Value{v}, repl.results[:2]...)
+	for i, res := range repl.results {
+		repl.scope.Bind(fmt.Sprintf("*%d", i+1), res)
+	}
+	return v, nil
+}
+
+func (repl *REPL) resetResults() {
+	repl.results = []sabre.Value{sabre.Nil{}, sabre.Nil{}, sabre.Nil{}}
+	for _, sym := range []string{"*1", "*2", "*3", "*e"} {
+		repl.scope.Bind(sym, sabre.Nil{})
+	}
+}
+
+func (repl *REPL) printResult(form, v sabre.Value, took time.Duration) error {
+	if repl.prepl != nil {
+		return repl.prepl.ret(repl.output, form, v, repl.currentNamespace(), took)
+	}
 	return repl.print(v)
 }
 
+func (repl *REPL) printErr(err error) error {
+	if repl.prepl != nil {
+		return repl.prepl.err(repl.output, err)
+	}
+	return repl.print(err)
+}
+
+// Write writes to the output of the REPL. In prepl mode, the data is written
+// as an ':out' message. Functions bound in the scope can use the REPL as the
+// writer for printing.
 func (repl *REPL) Write(b []byte) (int, error) {
+	if repl.prepl != nil {
+		if err := repl.prepl.out(repl.output, string(b)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
 	return repl.output.Write(b)
 }
 
@@ -117,7 +186,7 @@ func (repl *REPL) print(v interface{}) error {
 	return repl.printer(repl.output, v)
 }
 
-func (repl *REPL) read() (sabre.Value, error) {
+func (repl *REPL) read() (sabre.Value, *commandCall, error) {
 	var src string
 	lineNo := 1
 
@@ -125,15 +194,25 @@ func (repl *REPL) read() (sabre.Value, error) {
 		repl.setPrompt(lineNo > 1)
 
 		line, err := repl.input.Readline()
+		if errors.Is(err, ErrInterrupted) {
+			return nil, nil, nil // discard the form being entered.
+		}
+
 		err = repl.mapInputErr(err)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if lineNo == 1 && repl.commands != nil {
+			if call := repl.command(line); call != nil {
+				return nil, call, nil
+			}
 		}
 
 		src += line + "\n"
 
 		if strings.TrimSpace(src) == "" {
-			return nil, nil
+			return nil, nil, nil
 		}
 
 		rd := repl.factory.NewReader(strings.NewReader(src))
@@ -146,15 +225,25 @@ func (repl *REPL) read() (sabre.Value, error) {
 				continue
 			}
 
-			return nil, err
+			return nil, nil, err
 		}
 
-		return form, nil
+		repl.spans = rd.SourceMap()
+		return form, nil, nil
+	}
+}
+
+func (repl *REPL) setScope(scope sabre.Scope) {
+	repl.scope = scope
+	repl.currentNamespace = func() string { return "" }
+
+	if ns, ok := scope.(NamespacedScope); ok {
+		repl.currentNamespace = ns.CurrentNS
 	}
 }
 
 func (repl *REPL) setPrompt(multiline bool) {
-	if repl.prompt == "" {
+	if repl.prompt == "" || repl.prepl != nil {
 		return
 	}
 
@@ -170,7 +259,7 @@ func (repl *REPL) setPrompt(multiline bool) {
 }
 
 func (repl *REPL) printBanner() {
-	if repl.banner != "" {
-		fmt.Println(repl.banner)
+	if repl.banner != "" && repl.prepl == nil {
+		fmt.Fprintln(repl.output, repl.banner)
 	}
 }
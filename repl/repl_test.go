@@ -0,0 +1,205 @@
+package repl_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+	"github.com/spy16/sabre/repl"
+)
+
+func TestServeTCP(t *testing.T) {
+	t.Parallel()
+
+	root := sabre.New()
+	_ = root.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+
+	factory := func(out io.Writer) sabre.Scope {
+		scope := sabre.NewScope(root)
+		_ = scope.BindGo("say", func(s string) { fmt.Fprint(out, s) })
+		return scope
+	}
+
+	table := []struct {
+		name  string
+		opts  []repl.Option
+		input string
+		want  []string
+	}{
+		{
+			name:  "Plain",
+			opts:  []repl.Option{repl.WithPrompts("=>", "|")},
+			input: "(inc\n1)\n",
+			want:  []string{"=>  | 2"},
+		},
+		{
+			name:  "PreplEDN",
+			opts:  []repl.Option{repl.WithPrepl(repl.PreplEDN)},
+			input: "(say \"hi\")\n(inc \"a\")\n)\n",
+			want: []string{
+				`{:tag :out :val "hi"}`,
+				`{:form "(say \"hi\")" :ms `,
+				`{:tag :err :val `,
+				`{:tag :err :val "syntax error in 'REPL' (Line 1 Col 1): unmatched delimiter ')'"}`,
+			},
+		},
+		{
+			name:  "PreplJSON",
+			opts:  []repl.Option{repl.WithPrepl(repl.PreplJSON)},
+			input: "(inc 41)\n",
+			want:  []string{`{"form":"(inc 41)","ms":`},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to listen: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			served := make(chan error, 1)
+			go func() { served <- repl.ServeTCP(ctx, l, factory, tt.opts...) }()
+
+			conn, err := net.Dial("tcp", l.Addr().String())
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer conn.Close()
+
+			_, _ = io.WriteString(conn, tt.input)
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+			rd := bufio.NewReader(conn)
+			for _, want := range tt.want {
+				line, err := rd.ReadString('\n')
+				if err != nil {
+					t.Fatalf("failed to read output: %v", err)
+				}
+
+				if !strings.HasPrefix(line, want) {
+					t.Errorf("got line %q, want prefix %q", line, want)
+				}
+			}
+
+			cancel()
+			if err := <-served; err != context.Canceled {
+				t.Errorf("ServeTCP() expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
+
+func TestREPL_Loop_Cancel(t *testing.T) {
+	t.Parallel()
+
+	scope := sabre.New()
+	_ = scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+
+	in := &fakeInput{lines: []string{"((fn* [n] (recur (inc n))) 0)"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var out strings.Builder
+	err := repl.New(scope, repl.WithInput(in, nil), repl.WithOutput(&out)).Loop(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Loop() expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if !strings.Contains(out.String(), "evaluation cancelled") {
+		t.Errorf("Loop() expected cancelled evaluation, got %q", out.String())
+	}
+}
+
+func TestREPL_Commands(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "sabre-repl")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "lib.lisp")
+	if err := ioutil.WriteFile(file, []byte("(def m (macro* [x] `(inc ~x)))\n(m 10)\n"), 0600); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	scope := sabre.New()
+	_ = scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+
+	in := &fakeInput{lines: []string{
+		"(inc 1)", "(inc *1)", "*2",
+		":doc if",
+		":load " + file,
+		":doc m",
+		":macroexpand (m 1)",
+		":time (m 1)",
+		":scope in",
+		":foo",
+		"(foo)", "*e",
+		":unbind inc", "(inc 5)",
+		":reset", "*1", "*e", "m", "(inc 5)",
+		":quit",
+		"(inc 100)",
+	}}
+
+	var out strings.Builder
+	err = repl.New(scope,
+		repl.WithInput(in, nil),
+		repl.WithOutput(&out),
+		repl.WithCommands(repl.DefaultCommands()...),
+	).Loop(context.Background())
+	if err != nil {
+		t.Fatalf("Loop() unexpected error: %v", err)
+	}
+
+	got := out.String()
+	want := []string{
+		"2\n3\n2\n",
+		"(if ...)\n  special form\n",
+		"11\n(m x)\n  macro\n",
+		"(inc 1)\n2\nElapsed time: ",
+		"inc  function\n:foo\n",
+		"unable to resolve symbol: foo\nAny{",
+		"unable to resolve symbol: inc\n",
+		"nil\nnil\n",
+		"unable to resolve symbol: m\n6\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("expected output to contain %q, got %q", w, got)
+		}
+	}
+
+	if strings.Contains(got, "101") {
+		t.Errorf("expected :quit to end the session, got %q", got)
+	}
+}
+
+type fakeInput struct {
+	lines []string
+}
+
+func (in *fakeInput) SetPrompt(string) {}
+
+func (in *fakeInput) Readline() (string, error) {
+	if len(in.lines) == 0 {
+		time.Sleep(time.Second)
+		return "", io.EOF
+	}
+
+	line := in.lines[0]
+	in.lines = in.lines[1:]
+	return line, nil
+}
@@ -0,0 +1,73 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/spy16/sabre"
+)
+
+// ScopeFactory returns the scope for a new REPL session. out is the REPL of
+// the session and can be used by functions bound in the scope for printing
+// to the client (See REPL.Write()). Returning a child scope of a shared root
+// scope (e.g., sabre.NewScope(root)) keeps bindings of sessions isolated
+// while 'def' still binds in the root scope.
+type ScopeFactory func(out io.Writer) sabre.Scope
+
+// ServeTCP accepts connections on the listener and runs a REPL for each of
+// them using a scope created by the factory. Options are applied to every
+// REPL before setting the connection as its input and output (e.g., use
+// WithPrepl() to serve programmatic clients). When the context is cancelled,
+// the listener and all connections are closed, running evaluations are
+// cancelled and ServeTCP returns after all sessions end.
+func ServeTCP(ctx context.Context, l net.Listener, factory ScopeFactory, opts ...Option) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveConn(ctx, conn, factory, opts)
+		}()
+	}
+}
+
+func serveConn(ctx context.Context, conn net.Conn, factory ScopeFactory, opts []Option) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	in := &lineReader{
+		scanner: bufio.NewScanner(conn),
+		out:     conn,
+	}
+
+	repl := New(nil, append(opts, WithInput(in, nil), WithOutput(conn))...)
+	repl.setScope(factory(repl))
+
+	_ = repl.Loop(ctx)
+}
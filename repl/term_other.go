@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package repl
+
+import "errors"
+
+func isTerminal(fd uintptr) bool { return false }
+
+func makeRaw(fd uintptr) (func() error, error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
+
+func terminalWidth(fd uintptr) int { return 80 }
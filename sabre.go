@@ -26,12 +26,14 @@ func Eval(scope Scope, form Value) (Value, error) {
 // ReadEval consumes data from reader 'r' till EOF, parses into forms
 // and evaluates all the forms obtained and returns the result.
 func ReadEval(scope Scope, r io.Reader) (Value, error) {
-	mod, err := NewReader(r).All()
+	rd := NewReader(r)
+	mod, err := rd.All()
 	if err != nil {
 		return nil, err
 	}
 
-	return Eval(scope, mod)
+	v, err := Eval(scope, mod)
+	return v, rd.SourceMap().Annotate(err)
 }
 
 // ReadEvalStr is a convenience wrapper for Eval that reads forms from
@@ -48,24 +50,46 @@ type Scope interface {
 }
 
 func newEvalErr(v Value, err error) EvalError {
-	if ee, ok := err.(EvalError); ok {
+	return evalErrAt(v, nil, err)
+}
+
+// evalErrAt is same as newEvalErr() but also records the slot the form was
+// read into (e.g., &list.Values[i]) so that forms without a position (e.g.,
+// atoms) can be located using the source map. See SourceMap.Annotate(). If
+// err is already an EvalError without position, the position of v is used.
+func evalErrAt(v Value, slot *Value, err error) EvalError {
+	ee, isEvalErr := err.(EvalError)
+	if ptr, ok := err.(*EvalError); ok && ptr != nil {
+		ee, isEvalErr = *ptr, true
+	}
+
+	if isEvalErr {
+		if ee.Line == 0 {
+			// the failed form has no position (e.g., atoms). report the
+			// position of the nearest enclosing form that has one.
+			ee.Position = getPosition(v)
+		}
 		return ee
-	} else if ee, ok := err.(*EvalError); ok && ee != nil {
-		return *ee
 	}
 
 	return EvalError{
 		Position: getPosition(v),
 		Cause:    err,
 		Form:     v,
+		slot:     slot,
 	}
 }
 
-// EvalError represents error during evaluation.
+// EvalError represents error during evaluation. Span is set only if the
+// form was read by a reader whose source map was used to annotate the error
+// (e.g., when using ReadEval). See SourceMap.Annotate().
 type EvalError struct {
 	Position
+	Span  Span
 	Cause error
 	Form  Value
+
+	slot *Value
 }
 
 // Unwrap returns the underlying cause of this error.
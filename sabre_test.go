@@ -1,6 +1,7 @@
 package sabre_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -103,6 +104,40 @@ func TestEval(t *testing.T) {
 			src:  `(ten? 10)`,
 			want: sabre.Bool(true),
 		},
+		{
+			name: "AnonymousFn",
+			src:  `(#(do [%2 %1 %&]) 1 2 3)`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.Int64(2),
+				sabre.Int64(1),
+				&sabre.List{Values: sabre.Values{sabre.Int64(3)}},
+			}},
+		},
+		{
+			name: "LocalSlots",
+			src:  `(let* [a 1 b [a] a 2] ((fn* [a & b] [a b]) a b))`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.Int64(2),
+				&sabre.List{Values: sabre.Values{
+					sabre.Vector{Values: sabre.Values{sabre.Int64(1)}},
+				}},
+			}},
+		},
+		{
+			name: "VarQuote",
+			src:  `(def x 10) #'x`,
+			want: sabre.Int64(10),
+		},
+		{
+			name: "VarQuoteSpecialForm",
+			src:  `(str #'do (var do))`,
+			want: sabre.String("SpecialForm{name=do}SpecialForm{name=do}"),
+		},
+		{
+			name:    "VarQuoteUnbound",
+			src:     `#'unknown`,
+			wantErr: true,
+		},
 		{
 			name:    "ReadError",
 			src:     `123 [] (`,
@@ -138,6 +173,30 @@ func TestEval(t *testing.T) {
 	}
 }
 
+func TestReadEval_ErrorSpan(t *testing.T) {
+	t.Parallel()
+
+	_, err := sabre.ReadEvalStr(sabre.New(), "(def x 1)\n  (x 2)")
+
+	var ee sabre.EvalError
+	if !errors.As(err, &ee) {
+		t.Fatalf("ReadEvalStr() expected EvalError, got %#v", err)
+	}
+
+	want := sabre.Span{
+		File:  "<string>",
+		Start: sabre.Location{Offset: 12, Line: 2, Column: 3},
+		End:   sabre.Location{Offset: 17, Line: 2, Column: 8},
+	}
+	if ee.Span != want {
+		t.Errorf("Span got = %s, want = %s", ee.Span, want)
+	}
+
+	if ee.Line != 2 || ee.Column != 3 {
+		t.Errorf("Position got = %s, want line 2 column 3", ee.Position)
+	}
+}
+
 func asserter(t *testing.T) func(sabre.Scope, []sabre.Value) (sabre.Value, error) {
 	return func(scope sabre.Scope, exprs []sabre.Value) (sabre.Value, error) {
 		var res sabre.Value
@@ -4,25 +4,104 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // ErrResolving is returned when a scope implementation fails to resolve
 // a binding for given symbol.
 var ErrResolving = errors.New("unable to resolve symbol")
 
+// ErrSealed is returned when modifying the bindings of a sealed scope.
+var ErrSealed = errors.New("scope is sealed")
+
+// BindingLister can be implemented by Scope implementations to allow listing
+// the symbols bound in the scope. Tools like REPL completion and language
+// server use this to discover the available symbols. See Bindings().
+type BindingLister interface {
+	// Bindings returns a copy of the bindings in the scope excluding the
+	// bindings of the parent scope.
+	Bindings() map[string]Value
+}
+
+// Unbinder can be implemented by Scope implementations to allow removing
+// bindings from the scope.
+type Unbinder interface {
+	// Unbind removes the binding of the symbol from the scope. Bindings of
+	// the parent scope are not affected.
+	Unbind(symbol string) error
+}
+
+// Snapshotter can be implemented by Scope implementations to allow saving
+// the bindings of the scope and restoring them later. This is useful for
+// resetting the scope after evaluating rules, tests or REPL sessions.
+type Snapshotter interface {
+	// Snapshot captures the bindings of the scope excluding the bindings of
+	// the parent scope. Later changes to the scope must not affect it.
+	Snapshot() Snapshot
+
+	// Restore replaces the bindings of the scope with the ones captured in
+	// the snapshot. A snapshot can be restored multiple times.
+	Restore(snapshot Snapshot) error
+}
+
+// Snapshot represents bindings captured by Snapshotter. The contents are
+// specific to the scope implementation that created it.
+type Snapshot interface{}
+
+// Bindings returns all the bindings visible from the scope by walking the
+// parent chain. Bindings of a scope shadow the bindings of its parents.
+// Scopes that do not implement BindingLister are skipped.
+func Bindings(scope Scope) map[string]Value {
+	bindings := map[string]Value{}
+	for s := scope; s != nil; s = s.Parent() {
+		for w, ok := s.(scopeWrapper); ok; w, ok = s.(scopeWrapper) {
+			s = w.unwrap()
+		}
+
+		lister, ok := s.(BindingLister)
+		if !ok {
+			continue
+		}
+
+		for symbol, v := range lister.Bindings() {
+			if _, found := bindings[symbol]; !found {
+				bindings[symbol] = v
+			}
+		}
+	}
+
+	return bindings
+}
+
 // New initializes a new scope with all the core bindings.
 func New() *MapScope {
-	scope := &MapScope{
-		parent:   nil,
-		mu:       new(sync.RWMutex),
-		bindings: map[string]Value{},
-	}
+	scope := NewScope(nil)
+	bindCore(scope)
+	return scope
+}
 
+func bindCore(scope Scope) {
 	scope.Bind("macroexpand", ValueOf(func(scope Scope, v Value) (Value, error) {
 		f, _, err := MacroExpand(scope, v)
 		return f, err
 	}))
 
+	scope.Bind("pr-str", ValueOf(func(vals ...Value) String {
+		return String(PrStr(vals...))
+	}))
+	scope.Bind("print-str", ValueOf(func(vals ...Value) String {
+		return String(PrintStr(vals...))
+	}))
+	scope.Bind("str", ValueOf(func(vals ...Value) String {
+		return String(Str(vals...))
+	}))
+
+	bindRegex(scope)
+	bindStrings(scope)
+	bindAsync(scope)
+	bindParallel(scope)
+	bindStructs(scope)
+
 	scope.Bind("quote", SimpleQuote)
 	scope.Bind("syntax-quote", SyntaxQuote)
 
@@ -33,8 +112,7 @@ func New() *MapScope {
 	scope.Bind("do", Do)
 	scope.Bind("def", Def)
 	scope.Bind("recur", Recur)
-
-	return scope
+	scope.Bind("var", Var)
 }
 
 // NewScope returns an instance of MapScope with no bindings. If you need
@@ -52,6 +130,7 @@ type MapScope struct {
 	parent   Scope
 	mu       *sync.RWMutex
 	bindings map[string]Value
+	sealed   bool
 }
 
 // Parent returns the parent scope of this scope.
@@ -62,6 +141,10 @@ func (scope *MapScope) Bind(symbol string, v Value) error {
 	scope.mu.Lock()
 	defer scope.mu.Unlock()
 
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot bind %v", ErrSealed, symbol)
+	}
+
 	scope.bindings[symbol] = v
 	return nil
 }
@@ -84,8 +167,161 @@ func (scope *MapScope) Resolve(symbol string) (Value, error) {
 	return v, nil
 }
 
+// Bindings returns a copy of the bindings in this scope. Bindings of the
+// parent scope are not included.
+func (scope *MapScope) Bindings() map[string]Value {
+	scope.mu.RLock()
+	defer scope.mu.RUnlock()
+
+	return copyBindings(scope.bindings)
+}
+
+// Unbind removes the binding of the symbol from this scope. Returns error if
+// the symbol is not bound in this scope.
+func (scope *MapScope) Unbind(symbol string) error {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot unbind %v", ErrSealed, symbol)
+	}
+
+	if _, found := scope.bindings[symbol]; !found {
+		return fmt.Errorf("%w: %v", ErrResolving, symbol)
+	}
+
+	delete(scope.bindings, symbol)
+	return nil
+}
+
+// Snapshot captures the bindings in this scope. Bindings of the parent scope
+// are not included.
+func (scope *MapScope) Snapshot() Snapshot {
+	return mapSnapshot(scope.Bindings())
+}
+
+// Restore replaces the bindings in this scope with the ones captured using
+// Snapshot() of a MapScope.
+func (scope *MapScope) Restore(snapshot Snapshot) error {
+	snap, ok := snapshot.(mapSnapshot)
+	if !ok {
+		return fmt.Errorf("cannot restore snapshot of type %T", snapshot)
+	}
+
+	bindings := copyBindings(snap)
+
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.sealed {
+		return fmt.Errorf("%w: cannot restore", ErrSealed)
+	}
+
+	scope.bindings = bindings
+	return nil
+}
+
+// Seal makes the bindings of this scope immutable. Bind, Unbind and Restore
+// return ErrSealed after sealing. Sealing is irreversible.
+func (scope *MapScope) Seal() {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	scope.sealed = true
+}
+
 // BindGo is similar to Bind but handles conversion of Go value 'v' to
 // sabre Value type. See `ValueOf()`
 func (scope *MapScope) BindGo(symbol string, v interface{}) error {
 	return scope.Bind(symbol, ValueOf(v))
 }
+
+type mapSnapshot map[string]Value
+
+// slotLayout assigns a slot to every local of a function or let* form. The
+// layout is computed once when the fn* or let* form is parsed so that every
+// invocation only fills in the values of the slots and resolving a local is
+// a single lookup. Later names shadow the earlier ones with the same name.
+type slotLayout struct {
+	names []string
+	slots map[string]int
+}
+
+func newSlotLayout(names []string) *slotLayout {
+	layout := &slotLayout{
+		names: names,
+		slots: make(map[string]int, len(names)),
+	}
+
+	for i, name := range names {
+		layout.slots[name] = i
+	}
+	return layout
+}
+
+// newLocalScope returns a scope holding the arguments of a function call or
+// the bindings of a let* form. values[i] is the value of i-th slot in the
+// layout.
+func newLocalScope(parent Scope, layout *slotLayout, values []Value) *localScope {
+	return &localScope{
+		parent: parent,
+		layout: layout,
+		values: values,
+	}
+}
+
+// localScope resolves symbols using the slots of the layout. Since functions
+// and let* forms have only a few locals, this is faster than MapScope and
+// avoids allocating a map and locking for every invocation.
+type localScope struct {
+	parent Scope
+	layout *slotLayout
+	values []Value
+
+	// bindings made using Bind() (e.g., 'def' when there is no parent).
+	mu    sync.Mutex
+	extra atomic.Value
+}
+
+func (ls *localScope) Parent() Scope { return ls.parent }
+
+func (ls *localScope) Bind(symbol string, v Value) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	extra, _ := ls.extra.Load().(*persistentMap)
+	ls.extra.Store(extra.set(symbol, v))
+	return nil
+}
+
+func (ls *localScope) Resolve(symbol string) (Value, error) {
+	if extra, ok := ls.extra.Load().(*persistentMap); ok {
+		if v, found := extra.get(symbol); found {
+			return v, nil
+		}
+	}
+
+	if slot, found := ls.layout.slots[symbol]; found {
+		return ls.values[slot], nil
+	}
+
+	if ls.parent != nil {
+		return ls.parent.Resolve(symbol)
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrResolving, symbol)
+}
+
+func (ls *localScope) Bindings() map[string]Value {
+	bindings := make(map[string]Value, len(ls.values))
+	for name, slot := range ls.layout.slots {
+		bindings[name] = ls.values[slot]
+	}
+
+	if extra, ok := ls.extra.Load().(*persistentMap); ok {
+		extra.each(func(symbol string, v Value) {
+			bindings[symbol] = v
+		})
+	}
+	return bindings
+}
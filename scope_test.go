@@ -1,13 +1,20 @@
 package sabre_test
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/spy16/sabre"
 )
 
-var _ sabre.Scope = (*sabre.MapScope)(nil)
+var (
+	_ sabre.Scope         = (*sabre.MapScope)(nil)
+	_ sabre.BindingLister = (*sabre.MapScope)(nil)
+	_ sabre.Unbinder      = (*sabre.MapScope)(nil)
+	_ sabre.Snapshotter   = (*sabre.MapScope)(nil)
+)
 
 func TestMapScope_Resolve(t *testing.T) {
 	tests := []struct {
@@ -62,3 +69,90 @@ func TestMapScope_Resolve(t *testing.T) {
 		})
 	}
 }
+
+func TestMapScope_Bindings(t *testing.T) {
+	parent := sabre.NewScope(nil)
+	_ = parent.Bind("pi", sabre.Float64(3.1412))
+
+	scope := sabre.NewScope(parent)
+	_ = scope.Bind("hello", sabre.String("Hello World!"))
+
+	bindings := scope.Bindings()
+	want := map[string]sabre.Value{"hello": sabre.String("Hello World!")}
+	if !reflect.DeepEqual(bindings, want) {
+		t.Errorf("Bindings() got = %v, want %v", bindings, want)
+	}
+
+	delete(bindings, "hello")
+	if _, err := scope.Resolve("hello"); err != nil {
+		t.Errorf("Bindings() must return a copy, Resolve() error = %v", err)
+	}
+}
+
+func TestBindings(t *testing.T) {
+	parent := sabre.NewScope(nil)
+	_ = parent.Bind("pi", sabre.Float64(3.1412))
+	_ = parent.Bind("hello", sabre.String("Hello"))
+
+	scope := sabre.NewScope(parent)
+	_ = scope.Bind("hello", sabre.String("Hello World!"))
+
+	want := map[string]sabre.Value{
+		"pi":    sabre.Float64(3.1412),
+		"hello": sabre.String("Hello World!"),
+	}
+
+	got := sabre.Bindings(sabre.WithContext(context.Background(), scope))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings() got = %v, want %v", got, want)
+	}
+}
+
+func TestMapScope_Unbind(t *testing.T) {
+	parent := sabre.NewScope(nil)
+	_ = parent.Bind("pi", sabre.Float64(3.1412))
+
+	scope := sabre.NewScope(parent)
+	_ = scope.Bind("pi", sabre.Float64(3.14))
+
+	if err := scope.Unbind("pi"); err != nil {
+		t.Fatalf("Unbind() unexpected error: %v", err)
+	}
+
+	got, err := scope.Resolve("pi")
+	if err != nil || got != sabre.Float64(3.1412) {
+		t.Errorf("Resolve() expected binding from parent, got = %v, err = %v", got, err)
+	}
+
+	if err := scope.Unbind("pi"); !errors.Is(err, sabre.ErrResolving) {
+		t.Errorf("Unbind() expected ErrResolving, got %v", err)
+	}
+}
+
+func TestMapScope_Snapshot(t *testing.T) {
+	scope := sabre.New()
+	_ = scope.BindGo("inc", func(i sabre.Int64) sabre.Int64 { return i + 1 })
+	snapshot := scope.Snapshot()
+
+	for _, rule := range []string{"(def x (inc 1))", "(def x (inc 41))", "(def inc 10)"} {
+		if _, err := sabre.ReadEvalStr(scope, rule); err != nil {
+			t.Fatalf("ReadEvalStr() unexpected error: %v", err)
+		}
+
+		if err := scope.Restore(snapshot); err != nil {
+			t.Fatalf("Restore() unexpected error: %v", err)
+		}
+
+		if _, err := scope.Resolve("x"); err == nil {
+			t.Errorf("Restore() expected 'x' to be removed after '%s'", rule)
+		}
+
+		if got, err := sabre.ReadEvalStr(scope, "(inc 1)"); err != nil || got != sabre.Int64(2) {
+			t.Errorf("Restore() expected 'inc' to be restored, got = %v, err = %v", got, err)
+		}
+	}
+
+	if err := scope.Restore("not-a-snapshot"); err == nil {
+		t.Errorf("Restore() expected error for invalid snapshot")
+	}
+}
@@ -0,0 +1,158 @@
+package sabre
+
+import "fmt"
+
+// Location represents a point in the source stream.
+type Location struct {
+	Offset int // byte offset from the start of the stream.
+	Line   int // line number starting at 1.
+	Column int // rune column starting at 1.
+}
+
+// Span represents the range of source text a form was read from. End is the
+// location immediately after the last rune of the form.
+type Span struct {
+	File  string
+	Start Location
+	End   Location
+}
+
+// Position returns the start of the span as Position.
+func (s Span) Position() Position {
+	return Position{
+		File:   s.File,
+		Line:   s.Start.Line,
+		Column: s.Start.Column,
+	}
+}
+
+func (s Span) String() string {
+	file := s.File
+	if file == "" {
+		file = "<unknown>"
+	}
+
+	return fmt.Sprintf("%s:%d:%d-%d:%d", file,
+		s.Start.Line, s.Start.Column, s.End.Line, s.End.Column)
+}
+
+// SourceMap records the spans of forms read by a Reader. Lists and hash-maps
+// are looked up by identity and other forms carrying a Position (e.g., symbols,
+// vectors) by their start position. Since atoms (e.g., numbers, strings) have
+// no identity of their own, every form read as an element of a container or
+// a module is also recorded by the slot it occupies in the container. Atoms
+// are located using ElementSpan() or through the slot recorded in EvalError
+// (See Annotate()).
+type SourceMap struct {
+	forms map[interface{}]Span
+	slots map[*Value]Span
+}
+
+// Span returns the span of the given form if it was read by the reader that
+// owns this source map. Returns false for atoms. See ElementSpan().
+func (sm *SourceMap) Span(form Value) (Span, bool) {
+	if sm == nil {
+		return Span{}, false
+	}
+
+	key, ok := spanKey(form)
+	if !ok {
+		return Span{}, false
+	}
+
+	span, found := sm.forms[key]
+	return span, found
+}
+
+// ElementSpan returns the span of the i-th form in the container. Container
+// can be a list, vector, set or the module returned by Reader.All().
+func (sm *SourceMap) ElementSpan(container Value, i int) (Span, bool) {
+	if sm == nil {
+		return Span{}, false
+	}
+
+	var vals []Value
+	switch c := container.(type) {
+	case *List:
+		vals = c.Values
+	case Vector:
+		vals = c.Values
+	case Set:
+		vals = c.Values
+	case Module:
+		vals = c
+	}
+
+	if i < 0 || i >= len(vals) {
+		return Span{}, false
+	}
+
+	span, found := sm.slots[&vals[i]]
+	return span, found
+}
+
+// Annotate sets the span of the form that caused the error if err is an
+// EvalError and the form was read by the reader that owns this source map.
+// Other errors are returned as is.
+func (sm *SourceMap) Annotate(err error) error {
+	ee, ok := err.(EvalError)
+	if !ok || sm == nil {
+		return err
+	}
+
+	span, found := sm.slots[ee.slot]
+	if !found {
+		span, found = sm.Span(ee.Form)
+	}
+
+	if found {
+		ee.Span = span
+		ee.Position = span.Position()
+	}
+
+	return ee
+}
+
+func (sm *SourceMap) record(form Value, span Span) {
+	if key, ok := spanKey(form); ok {
+		sm.forms[key] = span
+	}
+}
+
+func (sm *SourceMap) recordElems(vals []Value, spans []Span) {
+	if len(vals) != len(spans) {
+		return
+	}
+
+	for i := range vals {
+		sm.slots[&vals[i]] = spans[i]
+	}
+}
+
+// reset discards all the spans recorded.
+func (sm *SourceMap) reset() {
+	sm.forms = map[interface{}]Span{}
+	sm.slots = map[*Value]Span{}
+}
+
+func newSourceMap() *SourceMap {
+	sm := &SourceMap{}
+	sm.reset()
+	return sm
+}
+
+func spanKey(form Value) (interface{}, bool) {
+	switch f := form.(type) {
+	case *List:
+		return f, f != nil
+
+	case *HashMap:
+		return f, f != nil
+	}
+
+	if pos := getPosition(form); pos.Line > 0 {
+		return pos, true
+	}
+
+	return nil, false
+}
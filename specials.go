@@ -64,6 +64,14 @@ var (
 		Name: "recur",
 		Parse: parseRecur,
 	}
+
+	// Var implements the (var symbol) form which is also read from #'symbol.
+	// Returns the value bound to the symbol without evaluating it. Unlike
+	// evaluating the symbol, special forms and macros are also returned.
+	Var = SpecialForm{
+		Name:  "var",
+		Parse: parseVar,
+	}
 )
 
 func fnParser(isMacro bool) func(scope Scope, forms []Value) (*Fn, error) {
@@ -147,17 +155,31 @@ func parseLet(scope Scope, args []Value) (*Fn, error) {
 		})
 	}
 
+	names := make([]string, len(bindings))
+	for i, b := range bindings {
+		names[i] = b.Name
+	}
+
+	// every binding expression sees only the previous bindings. layouts[i]
+	// has the slots of the first i bindings.
+	layouts := make([]*slotLayout, len(bindings)+1)
+	for i := range layouts {
+		layouts[i] = newSlotLayout(names[:i])
+	}
+
 	return &Fn{
 		Func: func(scope Scope, _ []Value) (Value, error) {
-			letScope := NewScope(scope)
-			for _, b := range bindings {
-				v, err := b.Expr.Eval(letScope)
+			// values never grows beyond its capacity so that scopes of
+			// previous bindings are not affected by append.
+			values := make([]Value, 0, len(bindings))
+			for i, b := range bindings {
+				v, err := b.Expr.Eval(newLocalScope(scope, layouts[i], values))
 				if err != nil {
 					return nil, err
 				}
-				_ = letScope.Bind(b.Name, v)
+				values = append(values, v)
 			}
-			return Module(args[1:]).Eval(letScope)
+			return Module(args[1:]).Eval(newLocalScope(scope, layouts[len(bindings)], values))
 		},
 	}, nil
 }
@@ -248,6 +270,23 @@ func parseSimpleQuote(scope Scope, forms []Value) (*Fn, error) {
 	}, nil
 }
 
+func parseVar(scope Scope, forms []Value) (*Fn, error) {
+	if err := verifyArgCount([]int{1}, forms); err != nil {
+		return nil, err
+	}
+
+	sym, isSymbol := forms[0].(Symbol)
+	if !isSymbol {
+		return nil, fmt.Errorf("var expects a symbol, not '%s'", reflect.TypeOf(forms[0]))
+	}
+
+	return &Fn{
+		Func: func(scope Scope, _ []Value) (Value, error) {
+			return sym.resolveValue(scope)
+		},
+	}, nil
+}
+
 func parseSyntaxQuote(scope Scope, forms []Value) (*Fn, error) {
 	if err := verifyArgCount([]int{1}, forms); err != nil {
 		return nil, err
@@ -310,7 +349,8 @@ func analyze(scope Scope, form Value) error {
 		}
 
 	case *List:
-		return f.parse(scope)
+		_, _, err := f.parse(scope)
+		return err
 
 	case String:
 		return nil
@@ -493,3 +533,66 @@ func accessMember(target reflect.Value, member string) (reflect.Value, error) {
 	return reflect.Value{}, fmt.Errorf("value of type '%s' has no member named '%s'",
 		target.Type(), member)
 }
+
+// Members returns the sorted names of exported methods and fields of 'v' that
+// can be accessed using the 'target.Member' syntax. If 'v' is an Any value,
+// members of the wrapped Go value are returned.
+func Members(v Value) []string {
+	target := reflect.ValueOf(v)
+	if any, isAny := v.(Any); isAny {
+		target = any.V
+	}
+
+	if !target.IsValid() {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name[0] >= 'a' && name[0] <= 'z' || seen[name] {
+			return
+		}
+		seen[name] = true
+	}
+
+	rt := target.Type()
+	for i := 0; i < rt.NumMethod(); i++ {
+		add(rt.Method(i).Name)
+	}
+
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	structFields(rt, add, map[reflect.Type]bool{})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// structFields calls add for all exported fields of the struct type including
+// the ones promoted from embedded structs.
+func structFields(rt reflect.Type, add func(name string), visited map[reflect.Type]bool) {
+	if rt.Kind() != reflect.Struct || visited[rt] {
+		return
+	}
+	visited[rt] = true
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath == "" {
+			add(field.Name)
+		}
+
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			structFields(ft, add, visited)
+		}
+	}
+}
@@ -116,6 +116,22 @@ func TestDot(t *testing.T) {
 	}
 }
 
+func TestMembers(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"Bar", "BarPtr", "Enabled", "Name"}
+	got := sabre.Members(sabre.ValueOf(&Foo{}))
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Members() want=%v, got=%v", want, got)
+	}
+
+	want = []string{"Bar", "Enabled", "Name"}
+	got = sabre.Members(sabre.ValueOf(Foo{}))
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Members() want=%v, got=%v", want, got)
+	}
+}
+
 // Foo is a dummy type for member access tests.
 type Foo struct {
 	Name          string
@@ -0,0 +1,201 @@
+package sabre
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// bindStrings binds the string library functions under the 'string/' prefix.
+// All indices used by these functions are in runes, not bytes.
+func bindStrings(scope Scope) {
+	fns := map[string]interface{}{
+		"join":         strJoin,
+		"split":        strSplit,
+		"trim":         func(s String) String { return String(strings.TrimSpace(string(s))) },
+		"upper-case":   func(s String) String { return String(strings.ToUpper(string(s))) },
+		"lower-case":   func(s String) String { return String(strings.ToLower(string(s))) },
+		"capitalize":   strCapitalize,
+		"starts-with?": func(s, prefix String) bool { return strings.HasPrefix(string(s), string(prefix)) },
+		"ends-with?":   func(s, suffix String) bool { return strings.HasSuffix(string(s), string(suffix)) },
+		"includes?":    strIncludes,
+		"index-of":     strIndexOf,
+		"replace":      replace,
+		"subs":         strSubs,
+		"format":       strFormat,
+		"blank?":       strBlank,
+	}
+
+	for name, fn := range fns {
+		_ = scope.Bind("string/"+name, ValueOf(fn))
+	}
+}
+
+// strJoin implements (string/join coll) and (string/join separator coll).
+func strJoin(args ...Value) (String, error) {
+	if err := verifyArgCount([]int{1, 2}, args); err != nil {
+		return "", err
+	}
+
+	sep, coll := "", args[len(args)-1]
+	if len(args) == 2 {
+		sep = Str(args[0])
+	}
+
+	seq, isSeq := coll.(Seq)
+	if !isSeq {
+		return "", fmt.Errorf("expecting a sequence, not '%s'", reflect.TypeOf(coll))
+	}
+
+	var parts []string
+	for ; !isEmptySeq(seq); seq = seq.Next() {
+		parts = append(parts, Str(seq.First()))
+	}
+
+	return String(strings.Join(parts, sep)), nil
+}
+
+// strSplit implements (string/split s sep) and (string/split s sep limit).
+// sep can be a regex or a string.
+func strSplit(s String, sep Value, limit ...int) (Value, error) {
+	n := -1
+	if len(limit) > 1 {
+		return nil, fmt.Errorf("call requires 2 or 3 argument(s), got %d", len(limit)+2)
+	} else if len(limit) == 1 && limit[0] > 0 {
+		n = limit[0]
+	}
+
+	var parts []string
+	switch sp := sep.(type) {
+	case Regex:
+		parts = sp.Split(string(s), n)
+
+	case String:
+		parts = strings.SplitN(string(s), string(sp), n)
+
+	case Character:
+		parts = strings.SplitN(string(s), string(sp), n)
+
+	default:
+		return nil, fmt.Errorf("separator must be regex, string or character, not '%s'",
+			reflect.TypeOf(sep))
+	}
+
+	vals := make(Values, len(parts))
+	for i, p := range parts {
+		vals[i] = String(p)
+	}
+	return Vector{Values: vals}, nil
+}
+
+func strCapitalize(s String) String {
+	r, size := utf8.DecodeRuneInString(string(s))
+	if size == 0 {
+		return s
+	}
+
+	return String(string(unicode.ToUpper(r)) + strings.ToLower(string(s)[size:]))
+}
+
+func strIncludes(s String, substr Value) (bool, error) {
+	switch sub := substr.(type) {
+	case String:
+		return strings.Contains(string(s), string(sub)), nil
+
+	case Character:
+		return strings.ContainsRune(string(s), rune(sub)), nil
+	}
+
+	return false, fmt.Errorf("expecting string or character, not '%s'", reflect.TypeOf(substr))
+}
+
+// strIndexOf implements (string/index-of s value) and (string/index-of s value
+// from). Returns the rune index of the first occurrence or nil.
+func strIndexOf(s String, value Value, from ...int) (Value, error) {
+	var sub string
+	switch v := value.(type) {
+	case String:
+		sub = string(v)
+	case Character:
+		sub = string(v)
+	default:
+		return nil, fmt.Errorf("expecting string or character, not '%s'", reflect.TypeOf(value))
+	}
+
+	runes := []rune(string(s))
+	start := 0
+	if len(from) > 0 {
+		start = from[0]
+	}
+
+	if start < 0 {
+		start = 0
+	} else if start > len(runes) {
+		return Nil{}, nil
+	}
+
+	idx := strings.Index(string(runes[start:]), sub)
+	if idx < 0 {
+		return Nil{}, nil
+	}
+
+	return Int64(start + utf8.RuneCountInString(string(runes[start:])[:idx])), nil
+}
+
+// strSubs implements (string/subs s start) and (string/subs s start end) using
+// rune indices.
+func strSubs(s String, start int, end ...int) (String, error) {
+	runes := []rune(string(s))
+
+	stop := len(runes)
+	if len(end) > 0 {
+		stop = end[0]
+	}
+
+	if start < 0 || stop > len(runes) || start > stop {
+		return "", fmt.Errorf("index out of bounds: [%d, %d) for string of length %d",
+			start, stop, len(runes))
+	}
+
+	return String(runes[start:stop]), nil
+}
+
+// strFormat formats the arguments using Go fmt verbs. Strings, numbers,
+// characters and booleans are passed to fmt as native Go values.
+func strFormat(format String, args ...Value) String {
+	goArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case String:
+			goArgs[i] = string(v)
+		case Int64:
+			goArgs[i] = int64(v)
+		case Float64:
+			goArgs[i] = float64(v)
+		case Bool:
+			goArgs[i] = bool(v)
+		case Character:
+			goArgs[i] = rune(v)
+		case Nil:
+			goArgs[i] = nil
+		default:
+			goArgs[i] = arg
+		}
+	}
+
+	return String(fmt.Sprintf(string(format), goArgs...))
+}
+
+func strBlank(v Value) (bool, error) {
+	switch s := v.(type) {
+	case Nil:
+		return true, nil
+
+	case String:
+		return strings.TrimSpace(string(s)) == "", nil
+	}
+
+	return false, fmt.Errorf("expecting string or nil, not '%s'", reflect.TypeOf(v))
+}
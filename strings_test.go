@@ -0,0 +1,96 @@
+package sabre_test
+
+import (
+	"testing"
+
+	"github.com/spy16/sabre"
+)
+
+func TestString_Seq(t *testing.T) {
+	t.Parallel()
+
+	s := sabre.String("λx🧠")
+	if got := s.First(); got != sabre.Character('λ') {
+		t.Errorf("First() got = %#v, want λ", got)
+	}
+
+	want := sabre.Values{sabre.Character('x'), sabre.Character('🧠')}
+	if got := s.Next(); !sabre.Compare(got, want) {
+		t.Errorf("Next() got = %#v, want = %#v", got, want)
+	}
+
+	if got := s.Size(); got != 3 {
+		t.Errorf("Size() got = %d, want = 3", got)
+	}
+
+	if got := sabre.String("").First(); got != (sabre.Nil{}) {
+		t.Errorf("First() got = %#v, want Nil{}", got)
+	}
+}
+
+func TestStringLibrary(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr bool
+	}{
+		{name: "Join", src: `(string/join [1 "a" \b nil])`, want: sabre.String("1ab")},
+		{name: "JoinSep", src: `(string/join ", " ["a" "b"])`, want: sabre.String("a, b")},
+		{name: "JoinString", src: `(string/join "-" "λβ")`, want: sabre.String("λ-β")},
+		{name: "JoinEmptyString", src: `(string/join "-" "")`, want: sabre.String("")},
+		{name: "JoinNotSeq", src: `(string/join 10)`, wantErr: true},
+		{
+			name: "SplitRegex",
+			src:  `(string/split "a1b22c" #"\d+")`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.String("a"), sabre.String("b"), sabre.String("c"),
+			}},
+		},
+		{
+			name: "SplitLimit",
+			src:  `(string/split "a,b,c" "," 2)`,
+			want: sabre.Vector{Values: sabre.Values{
+				sabre.String("a"), sabre.String("b,c"),
+			}},
+		},
+		{name: "Trim", src: `(string/trim "\t héllo \n")`, want: sabre.String("héllo")},
+		{name: "UpperCase", src: `(string/upper-case "héllo")`, want: sabre.String("HÉLLO")},
+		{name: "LowerCase", src: `(string/lower-case "ΑΒΓ")`, want: sabre.String("αβγ")},
+		{name: "Capitalize", src: `(string/capitalize "élan VITAL")`, want: sabre.String("Élan vital")},
+		{name: "StartsWith", src: `(string/starts-with? "λambda" "λ")`, want: sabre.Bool(true)},
+		{name: "EndsWith", src: `(string/ends-with? "lambda" "x")`, want: sabre.Bool(false)},
+		{name: "IncludesChar", src: `(string/includes? "naïve" \ï)`, want: sabre.Bool(true)},
+		{name: "IndexOf", src: `(string/index-of "λλab" "a")`, want: sabre.Int64(2)},
+		{name: "IndexOfFrom", src: `(string/index-of "abab" \a 1)`, want: sabre.Int64(2)},
+		{name: "IndexOfMissing", src: `(string/index-of "abc" "z")`, want: sabre.Nil{}},
+		{name: "Replace", src: `(string/replace "a-b" "-" "+")`, want: sabre.String("a+b")},
+		{name: "Subs", src: `(string/subs "λβγδ" 1 3)`, want: sabre.String("βγ")},
+		{name: "SubsToEnd", src: `(string/subs "λβγδ" 2)`, want: sabre.String("γδ")},
+		{name: "SubsOutOfBounds", src: `(string/subs "λβ" 1 5)`, wantErr: true},
+		{
+			name: "Format",
+			src:  `(string/format "%s is %d (%.1f) %c %v" "bob" 10 1.25 \λ [1 "a"])`,
+			want: sabre.String(`bob is 10 (1.2) λ [1 "a"]`),
+		},
+		{name: "BlankNil", src: `(string/blank? nil)`, want: sabre.Bool(true)},
+		{name: "BlankSpaces", src: `(string/blank? " \t ")`, want: sabre.Bool(true)},
+		{name: "NotBlank", src: `(string/blank? " x ")`, want: sabre.Bool(false)},
+		{name: "Str", src: `(str "λ" 1 \β)`, want: sabre.String("λ1β")},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sabre.ReadEvalStr(sabre.New(), tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !sabre.Compare(got, tt.want) {
+				t.Errorf("Eval() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package sabre
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StructToMap converts the Go struct (or pointer to struct) to a HashMap with
+// keyword keys named as described in StructFields(). Nested structs are
+// converted recursively.
+func StructToMap(v interface{}) (*HashMap, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("value of type '%s' is not a struct", reflect.TypeOf(v))
+	}
+
+	return structToMap(rv), nil
+}
+
+// MapToStruct sets the fields of the struct pointed to by target using the
+// entries of the hash-map. Field names are resolved as in StructToMap() and
+// values are converted to the types of the fields. Returns error if a key
+// does not name a field or a value cannot be converted.
+func MapToStruct(v Value, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to struct, not '%s'",
+			reflect.TypeOf(target))
+	}
+
+	res, err := convertStruct(rv.Elem().Type(), v)
+	if err != nil {
+		return err
+	}
+
+	rv.Elem().Set(res)
+	return nil
+}
+
+// StructField is a field of a Go struct that is converted to and from a
+// hash-map entry. See StructFields().
+type StructField struct {
+	Name      string
+	Index     []int
+	OmitEmpty bool
+}
+
+// Value returns the field of the struct value. Returns false if the field is
+// promoted through a nil embedded pointer.
+func (f StructField) Value(rv reflect.Value) (reflect.Value, bool) {
+	for i, idx := range f.Index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, true
+}
+
+// Settable returns the field of the addressable struct value for setting it.
+// Nil embedded pointers the field is promoted through are allocated.
+func (f StructField) Settable(rv reflect.Value) reflect.Value {
+	for i, idx := range f.Index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv
+}
+
+var fieldCache sync.Map // reflect.Type -> []StructField
+
+// StructFields returns the exported fields of the struct type that are
+// converted to and from hash-map entries. The name and options of a field
+// are taken from the first of the 'sabre', 'edn' and 'json' tags present on
+// the field (e.g., `sabre:"name,omitempty"`), falling back to the name of the
+// field. Fields tagged "-" are skipped. Similar to encoding/json, fields of
+// untagged embedded structs and pointers to structs are promoted unless
+// shadowed by a field with the same name, including the exported fields of
+// unexported embedded structs. Unexported embedded pointers are skipped since
+// they cannot be allocated.
+func StructFields(rt reflect.Type) []StructField {
+	if fields, found := fieldCache.Load(rt); found {
+		return fields.([]StructField)
+	}
+
+	var fields, promoted []StructField
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+
+		name, opts, tagged := fieldTag(sf)
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				if sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr {
+					continue
+				}
+
+				for _, f := range StructFields(ft) {
+					f.Index = append([]int{i}, f.Index...)
+					promoted = append(promoted, f)
+				}
+				continue
+			}
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		fields = append(fields, StructField{
+			Name:      name,
+			Index:     []int{i},
+			OmitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+
+	// fields of the struct shadow the promoted fields with the same name.
+	for _, f := range promoted {
+		if !hasField(fields, f.Name) {
+			fields = append(fields, f)
+		}
+	}
+
+	fieldCache.Store(rt, fields)
+	return fields
+}
+
+var fieldTags = []string{"sabre", "edn", "json"}
+
+// fieldTag returns the name and options from the first tag of the field
+// among fieldTags.
+func fieldTag(sf reflect.StructField) (name, opts string, tagged bool) {
+	for _, key := range fieldTags {
+		tag, found := sf.Tag.Lookup(key)
+		if !found {
+			continue
+		}
+
+		parts := strings.SplitN(tag, ",", 2)
+		if len(parts) == 2 {
+			opts = parts[1]
+		}
+		return parts[0], opts, parts[0] != ""
+	}
+
+	return "", "", false
+}
+
+func hasField(fields []StructField, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindField returns the field with the name. Names are matched exactly
+// first and then case-insensitively.
+func FindField(fields []StructField, name string) (StructField, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+
+	return StructField{}, false
+}
+
+// isPlainStruct returns true if the type is a struct type without methods.
+// Fields of such types are converted to HashMap by StructToMap().
+func isPlainStruct(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Struct &&
+		rt.NumMethod() == 0 && reflect.PtrTo(rt).NumMethod() == 0
+}
+
+func structToMap(rv reflect.Value) *HashMap {
+	fields := StructFields(rv.Type())
+
+	hm := &HashMap{Data: make(map[Value]Value, len(fields))}
+	for _, f := range fields {
+		fv, ok := f.Value(rv)
+		if !ok || (f.OmitEmpty && IsEmptyValue(fv)) {
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr && isPlainStruct(fv.Type().Elem()) {
+			if fv.IsNil() {
+				hm.Data[Keyword(f.Name)] = Nil{}
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if isPlainStruct(fv.Type()) {
+			hm.Data[Keyword(f.Name)] = structToMap(fv)
+			continue
+		}
+
+		hm.Data[Keyword(f.Name)] = valueOf(fv.Interface(), true)
+	}
+
+	return hm
+}
+
+// convertStruct creates a value of the struct type using the entries of the
+// hash-map.
+func convertStruct(rt reflect.Type, v Value) (reflect.Value, error) {
+	var entries map[Value]Value
+	switch m := v.(type) {
+	case *HashMap:
+		entries = m.Data
+
+	case MapView:
+		entries = m.realize().Data
+
+	default:
+		return reflect.Value{}, fmt.Errorf(
+			"value of type '%s' cannot be converted to '%s'", reflect.TypeOf(v), rt)
+	}
+
+	keys := make([]Value, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	fields := StructFields(rt)
+	res := reflect.New(rt).Elem()
+	for _, k := range keys {
+		var name string
+		switch key := k.(type) {
+		case Keyword:
+			name = string(key)
+
+		case String:
+			name = string(key)
+
+		case Symbol:
+			name = key.Value
+
+		default:
+			return reflect.Value{}, fmt.Errorf(
+				"key %s of type '%s' cannot name a field of '%s'", k, reflect.TypeOf(k), rt)
+		}
+
+		f, found := FindField(fields, name)
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown field '%s' in '%s'", name, rt)
+		}
+
+		fv := f.Settable(res)
+		c, err := convertArgTo(fv.Type(), reflectValues([]Value{entries[k]})[0])
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		fv.Set(c)
+	}
+
+	return res, nil
+}
+
+// IsEmptyValue returns true if the value is the zero value of its type or an
+// empty array, map, slice or string. Fields with the 'omitempty' option are
+// skipped when their values are empty.
+func IsEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	return rv.IsZero()
+}
+
+func bindStructs(scope Scope) {
+	_ = scope.Bind("->map", ValueOf(func(v Value) (Value, error) {
+		switch val := v.(type) {
+		case *HashMap:
+			return val, nil
+
+		case Any:
+			if val.V.IsValid() && val.V.CanInterface() {
+				return StructToMap(val.V.Interface())
+			}
+		}
+
+		return nil, fmt.Errorf("value of type '%s' is not a struct", reflect.TypeOf(v))
+	}))
+
+	_ = scope.Bind("map->", ValueOf(func(t Type, v Value) (Value, error) {
+		st := t.T
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+
+		if st.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("type '%s' is not a struct type", t.T)
+		}
+
+		rv, err := convertArgTo(t.T, reflectValues([]Value{v})[0])
+		if err != nil {
+			return nil, err
+		}
+		return Any{V: rv}, nil
+	}))
+}
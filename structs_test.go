@@ -0,0 +1,289 @@
+package sabre_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spy16/sabre"
+)
+
+type address struct {
+	City string `sabre:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type Audit struct {
+	CreatedBy string `sabre:"created-by"`
+}
+
+type user struct {
+	Audit
+	Name     string    `sabre:"name"`
+	Age      int       `json:"age"`
+	Email    string    `sabre:"email,omitempty" json:"mail"`
+	Password string    `sabre:"-"`
+	Tags     []string  `sabre:"tags,omitempty"`
+	Address  *address  `sabre:"address"`
+	Joined   time.Time `sabre:"joined,omitempty"`
+	internal bool
+}
+
+type Owner struct {
+	Team string `sabre:"team"`
+}
+
+type base struct {
+	ID int `sabre:"id"`
+}
+
+type account struct {
+	*Owner
+	base
+	Name string `sabre:"name"`
+}
+
+func (a account) Label() string { return a.Name + "#" + itoa(a.ID) }
+
+func TestStructs(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		src     string
+		want    sabre.Value
+		wantErr string
+	}{
+		{
+			name: "ReturnedStruct",
+			src:  `(->map (get-user))`,
+			want: hashMap(
+				sabre.Keyword("name"), sabre.String("bob"),
+				sabre.Keyword("age"), sabre.Int64(30),
+				sabre.Keyword("created-by"), sabre.String("admin"),
+				sabre.Keyword("address"), hashMap(
+					sabre.Keyword("city"), sabre.String("Paris"),
+				),
+			),
+		},
+		{
+			name: "ReturnedStructs",
+			src:  `(:city (->map (first (addresses))))`,
+			want: sabre.String("Oslo"),
+		},
+		{
+			name: "ReturnedStructFields",
+			src:  `(let* [u (get-user)] [u.Name u.CreatedBy u.Address.City])`,
+			want: sabre.Vector{Values: []sabre.Value{
+				sabre.String("bob"), sabre.String("admin"), sabre.String("Paris"),
+			}},
+		},
+		{
+			name: "ReturnedStructMethod",
+			src:  `(let* [a (get-account)] (a.Label))`,
+			want: sabre.String("main#7"),
+		},
+		{
+			name: "EmbeddedPointerToMap",
+			src:  `(->map (get-account))`,
+			want: hashMap(
+				sabre.Keyword("name"), sabre.String("main"),
+				sabre.Keyword("id"), sabre.Int64(7),
+				sabre.Keyword("team"), sabre.String("core"),
+			),
+		},
+		{
+			name: "EmbeddedPointerArg",
+			src:  `(account-team {:team "infra" :id 1})`,
+			want: sabre.String("infra 1"),
+		},
+		{
+			name: "StructArg",
+			src:  `(describe {:name "alice" :age 20 :tags ["a"] :address {:city "Rome"}})`,
+			want: sabre.String("alice 20 [a] Rome"),
+		},
+		{
+			name: "StructArgFieldNameFolding",
+			src:  `(describe {:NAME "alice"})`,
+			want: sabre.String("alice 0 []"),
+		},
+		{
+			name: "PointerStructArg",
+			src:  `(city {:city "Rome"})`,
+			want: sabre.String("Rome"),
+		},
+		{
+			name: "NilPointerStructArg",
+			src:  `(city nil)`,
+			want: sabre.String(""),
+		},
+		{
+			name: "RoundTrip",
+			src:  `(describe (get-user))`,
+			want: sabre.String("bob 30 [] Paris"),
+		},
+		{
+			name:    "UnknownField",
+			src:     `(describe {:name "alice" :password "secret"})`,
+			wantErr: "unknown field 'password' in 'sabre_test.user'",
+		},
+		{
+			name:    "UnconvertibleField",
+			src:     `(describe {:age "ten"})`,
+			wantErr: "field 'age': value of type 'sabre.String' cannot be converted to 'int'",
+		},
+		{
+			name: "ToMap",
+			src:  `(:city (->map (new-address)))`,
+			want: sabre.String("Berlin"),
+		},
+		{
+			name:    "ToMapNotStruct",
+			src:     `(->map 10)`,
+			wantErr: "is not a struct",
+		},
+		{
+			name: "FromMap",
+			src:  `(city-name (map-> address-type {:city "Lima"}))`,
+			want: sabre.String("Lima"),
+		},
+		{
+			name: "FromMapPointer",
+			src:  `(city (map-> address-ptr-type {:city "Lima"}))`,
+			want: sabre.String("Lima"),
+		},
+		{
+			name:    "FromMapNotStructType",
+			src:     `(map-> int-type {})`,
+			wantErr: "not a struct type",
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := sabre.New()
+			scope.BindGo("first", func(s sabre.Seq) sabre.Value { return s.First() })
+			scope.BindGo("get-user", func() user {
+				return user{
+					Audit:    Audit{CreatedBy: "admin"},
+					Name:     "bob",
+					Age:      30,
+					Password: "secret",
+					Address:  &address{City: "Paris"},
+				}
+			})
+			scope.BindGo("addresses", func() []address {
+				return []address{{City: "Oslo"}}
+			})
+			scope.BindGo("describe", func(u user) string {
+				city := ""
+				if u.Address != nil {
+					city = u.Address.City
+				}
+				return u.Name + " " + strings.TrimSpace(
+					strings.Join([]string{itoa(u.Age), "[" + strings.Join(u.Tags, " ") + "]", city}, " "))
+			})
+			scope.BindGo("city", func(a *address) string {
+				if a == nil {
+					return ""
+				}
+				return a.City
+			})
+			scope.BindGo("get-account", func() account {
+				return account{Owner: &Owner{Team: "core"}, base: base{ID: 7}, Name: "main"}
+			})
+			scope.BindGo("account-team", func(a account) string {
+				return a.Team + " " + itoa(a.ID)
+			})
+			scope.BindGo("city-name", func(a address) string { return a.City })
+			scope.BindGo("new-address", func() *address { return &address{City: "Berlin"} })
+			scope.BindGo("address-type", reflect.TypeOf(address{}))
+			scope.BindGo("address-ptr-type", reflect.TypeOf(&address{}))
+			scope.BindGo("int-type", reflect.TypeOf(0))
+
+			got, err := sabre.ReadEvalStr(scope, tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Eval() expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eval() unexpected error: %v", err)
+			}
+
+			if !sabre.Compare(tt.want, got) {
+				t.Errorf("Eval() want=%s, got=%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	t.Parallel()
+
+	joined := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	hm, err := sabre.StructToMap(&user{Name: "bob", Email: "bob@example.com", Joined: joined})
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+
+	if got := hm.Get(sabre.Keyword("email"), nil); got != sabre.String("bob@example.com") {
+		t.Errorf("expected sabre tag to take precedence, got %v", got)
+	}
+
+	if got := hm.Get(sabre.Keyword("address"), nil); got != (sabre.Nil{}) {
+		t.Errorf("expected nil for nil pointer field, got %v", got)
+	}
+
+	joinedVal, isAny := hm.Get(sabre.Keyword("joined"), nil).(sabre.Any)
+	if !isAny || !joinedVal.V.Interface().(time.Time).Equal(joined) {
+		t.Errorf("expected time field to be wrapped as Any, got %v", joinedVal)
+	}
+
+	for _, key := range []string{"Password", "internal", "tags", "Audit"} {
+		if got := hm.Get(sabre.Keyword(key), nil); got != nil {
+			t.Errorf("expected key %s to be excluded, got %v", key, got)
+		}
+	}
+
+	acc, err := sabre.StructToMap(account{Name: "main"})
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+
+	if got := acc.Get(sabre.Keyword("team"), nil); got != nil {
+		t.Errorf("expected fields of nil embedded pointer to be excluded, got %v", got)
+	}
+
+	if _, err := sabre.StructToMap(10); err == nil {
+		t.Errorf("StructToMap() expected error for non-struct value")
+	}
+
+	var u user
+	if err := sabre.MapToStruct(hm, &u); err != nil {
+		t.Fatalf("MapToStruct() unexpected error: %v", err)
+	}
+
+	if u.Name != "bob" || u.Email != "bob@example.com" || !u.Joined.Equal(joined) {
+		t.Errorf("MapToStruct() unexpected result: %+v", u)
+	}
+
+	if err := sabre.MapToStruct(hm, u); err == nil {
+		t.Errorf("MapToStruct() expected error for non-pointer target")
+	}
+}
+
+func hashMap(kvs ...sabre.Value) *sabre.HashMap {
+	hm := &sabre.HashMap{Data: map[sabre.Value]sabre.Value{}}
+	for i := 0; i < len(kvs); i += 2 {
+		hm.Data[kvs[i]] = kvs[i+1]
+	}
+	return hm
+}
+
+func itoa(i int) string {
+	return sabre.Int64(i).String()
+}
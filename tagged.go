@@ -0,0 +1,137 @@
+package sabre
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TagFunc is invoked by the reader with the form following a tag (e.g., the
+// string in '#inst "2020-01-01"') and should return the value the tagged
+// literal represents. See Reader.SetTag().
+type TagFunc func(form Value) (Value, error)
+
+// TagFuncOf returns a TagFunc that invokes the given Lisp function with the
+// tagged form (unevaluated) as argument.
+func TagFuncOf(scope Scope, fn Invokable) TagFunc {
+	return func(form Value) (Value, error) {
+		return fn.Invoke(scope, &List{
+			Values: Values{Symbol{Value: "quote"}, form},
+		})
+	}
+}
+
+// TaggedLiteral represents a tagged literal for which no TagFunc has been
+// registered in the reader. TaggedLiteral evaluates to itself and prints as
+// the original literal so that unknown tags can be round-tripped.
+type TaggedLiteral struct {
+	Tag  string
+	Form Value
+}
+
+// Eval returns itself.
+func (tl TaggedLiteral) Eval(_ Scope) (Value, error) { return tl, nil }
+
+// Compare returns true if 'v' is also a tagged literal with the same tag
+// and an equivalent form.
+func (tl TaggedLiteral) Compare(v Value) bool {
+	other, ok := v.(TaggedLiteral)
+	return ok && other.Tag == tl.Tag && Compare(tl.Form, other.Form)
+}
+
+func (tl TaggedLiteral) String() string { return "#" + tl.Tag + " " + PrStr(tl.Form) }
+
+// UUID represents a universally unique identifier read from '#uuid' tagged
+// literals.
+type UUID [16]byte
+
+// ParseUUID parses the canonical 8-4-4-4-12 hex representation of a UUID.
+func ParseUUID(s string) (UUID, error) {
+	var id UUID
+
+	parts := strings.Split(s, "-")
+	if len(s) != 36 || len(parts) != 5 {
+		return id, fmt.Errorf("invalid uuid '%s'", s)
+	}
+
+	b, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil || len(b) != len(id) {
+		return id, fmt.Errorf("invalid uuid '%s'", s)
+	}
+
+	copy(id[:], b)
+	return id, nil
+}
+
+// Eval returns itself.
+func (id UUID) Eval(_ Scope) (Value, error) { return id, nil }
+
+func (id UUID) String() string { return fmt.Sprintf("#uuid \"%s\"", id.Canonical()) }
+
+// Canonical returns the 8-4-4-4-12 hex representation of the UUID.
+func (id UUID) Canonical() string {
+	s := hex.EncodeToString(id[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+func readTagged(rd *Reader) (Value, error) {
+	tag, err := readToken(rd, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := rd.One()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: while reading tagged literal", ErrEOF)
+		}
+		return nil, err
+	}
+
+	fn, found := rd.tags[tag]
+	if found {
+		return fn(form)
+	}
+
+	if rd.DefaultTag != nil {
+		return rd.DefaultTag(tag, form)
+	}
+
+	return TaggedLiteral{Tag: tag, Form: form}, nil
+}
+
+func readInst(form Value) (Value, error) {
+	s, isString := form.(String)
+	if !isString {
+		return nil, fmt.Errorf("#inst expects a string, not '%s'", reflect.TypeOf(form))
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, string(s))
+	if err != nil {
+		t, err = time.Parse("2006-01-02", string(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid #inst '%s'", string(s))
+		}
+	}
+
+	return ValueOf(t), nil
+}
+
+func readUUID(form Value) (Value, error) {
+	s, isString := form.(String)
+	if !isString {
+		return nil, fmt.Errorf("#uuid expects a string, not '%s'", reflect.TypeOf(form))
+	}
+
+	return ParseUUID(string(s))
+}
+
+func defaultTagTable() map[string]TagFunc {
+	return map[string]TagFunc{
+		"inst": readInst,
+		"uuid": readUUID,
+	}
+}
@@ -36,6 +36,15 @@ type Seq interface {
 	Conj(vals ...Value) Seq
 }
 
+// isEmptySeq returns true if the sequence has no values. Empty strings are
+// checked separately since String.First() returns Nil{} for them.
+func isEmptySeq(seq Seq) bool {
+	if s, isString := seq.(String); isString {
+		return s == ""
+	}
+	return seq == nil || seq.First() == nil
+}
+
 // Compare compares two values in an identity independent manner. If
 // v1 has `Compare(Value) bool` method, the comparison is delegated to
 // it as `v1.Compare(v2)`.
@@ -152,10 +161,10 @@ func (vals Values) String() string {
 func evalValueList(scope Scope, vals []Value) ([]Value, error) {
 	var result []Value
 
-	for _, arg := range vals {
-		v, err := arg.Eval(scope)
+	for i := range vals {
+		v, err := vals[i].Eval(scope)
 		if err != nil {
-			return nil, newEvalErr(arg, err)
+			return nil, evalErrAt(vals[i], &vals[i], err)
 		}
 
 		result = append(result, v)